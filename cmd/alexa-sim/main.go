@@ -0,0 +1,121 @@
+// Command alexa-sim acts like alexa against a running handler, to exercise a smart home skill without the alexa console.
+//
+// It links accounts via a fake login with amazon server, discovers endpoints and sends directives like TurnOn or
+// ReportState, either interactively or from a script file with one command per line. Responses are pretty printed
+// and validated against the alexa smart home json schema.
+//
+//	alexa-sim -url http://localhost:8080/alexa [-script commands.txt]
+//
+// To link accounts the handler must use the fake login with amazon server as identity provider, set
+// authorization.LWA{TokenURL: ..., ProfileURL: ...} to the urls printed on the first link command.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/validator"
+)
+
+func main() {
+	url := flag.String("url", "", "url of the handler, required")
+	username := flag.String("username", "", "username for basic auth of the handler")
+	password := flag.String("password", "", "password for basic auth of the handler")
+	script := flag.String("script", "", "file with commands to run instead of reading them interactively")
+	schema := flag.String("schema", "", "file or url of the json schema to validate responses, defaults to the embedded alexa smart home schema")
+	validate := flag.Bool("validate", true, "validate responses against the json schema")
+	lwaAddr := flag.String("lwa", "127.0.0.1:8085", "address of the fake login with amazon server used to link accounts")
+	clientID := flag.String("client-id", "", "client id accepted by the fake login with amazon server, any client is accepted if empty")
+	clientSecret := flag.String("client-secret", "", "client secret accepted by the fake login with amazon server")
+	flag.Parse()
+
+	if *url == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	s := &simulator{
+		url:          *url,
+		username:     *username,
+		password:     *password,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		lwaAddr:      *lwaAddr,
+		clientID:     *clientID,
+		clientSecret: *clientSecret,
+		out:          os.Stdout,
+	}
+
+	if *validate {
+		s.validator = &validator.Validator{SchemaReference: *schema}
+	}
+
+	defer func() {
+		if s.lwa != nil {
+			s.lwa.Close()
+		}
+	}()
+
+	if *script == "" {
+		fmt.Fprintln(s.out, "type help to list all commands")
+		repl(s, os.Stdin)
+		return
+	}
+
+	f, err := os.Open(*script)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not open script; %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	if failed := run(s, f); failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d commands failed\n", failed)
+		os.Exit(1)
+	}
+}
+
+// repl reads and executes commands until quit or end of input
+func repl(s *simulator, in io.Reader) {
+	scanner := bufio.NewScanner(in)
+
+	for fmt.Fprint(s.out, "> "); scanner.Scan(); fmt.Fprint(s.out, "> ") {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "quit" || line == "exit" {
+			return
+		}
+
+		if err := s.execute(line); err != nil {
+			fmt.Fprintf(s.out, "error: %v\n", err)
+		}
+	}
+}
+
+// run executes all commands of the script and returns the number of failed commands
+func run(s *simulator, script io.Reader) (failed int) {
+	scanner := bufio.NewScanner(script)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if line == "quit" || line == "exit" {
+			return
+		}
+
+		fmt.Fprintf(s.out, "> %s\n", line)
+		if err := s.execute(line); err != nil {
+			fmt.Fprintf(s.out, "error in line %d: %v\n", n, err)
+			failed++
+		}
+	}
+
+	return
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+	"github.com/betom84/go-alexa/smarthome/smarthometest"
+	"github.com/betom84/go-alexa/smarthome/validator"
+)
+
+const usage = `commands:
+  link <email>                    link the account of the user via the fake login with amazon server
+  token <token>                   use the given bearer token for the following directives
+  discover                        discover endpoints
+  endpoints                       list discovered endpoints
+  turnon <endpointId>             send Alexa.PowerController.TurnOn
+  turnoff <endpointId>            send Alexa.PowerController.TurnOff
+  state <endpointId>              send Alexa.ReportState
+  send <namespace> <name> [endpointId] [payload]
+                                  send any directive, payload is a JSON object
+  help                            show this help
+  quit                            exit the simulator
+`
+
+// simulator acts like alexa by sending directives to the handler at url
+type simulator struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+
+	// validator checks every response against the json schema, optional
+	validator *validator.Validator
+
+	// lwa is the fake login with amazon server used to link accounts, started on lwaAddr on first use
+	lwa          *smarthometest.LWA
+	lwaAddr      string
+	clientID     string
+	clientSecret string
+
+	token     string
+	endpoints []discoverable.Endpoint
+
+	out io.Writer
+}
+
+// execute runs a single command, empty lines and comments starting with # are ignored
+func (s *simulator) execute(line string) error {
+	args := strings.Fields(line)
+	if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+		return nil
+	}
+
+	switch cmd := strings.ToLower(args[0]); cmd {
+	case "help":
+		fmt.Fprint(s.out, usage)
+		return nil
+	case "link":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: link <email>")
+		}
+		return s.link(args[1])
+	case "token":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: token <token>")
+		}
+		s.token = args[1]
+		return nil
+	case "discover":
+		return s.discover()
+	case "endpoints":
+		s.listEndpoints()
+		return nil
+	case "turnon", "turnoff", "state":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: %s <endpointId>", cmd)
+		}
+
+		builders := map[string]func(string) *smarthometest.DirectiveBuilder{
+			"turnon":  smarthometest.TurnOn,
+			"turnoff": smarthometest.TurnOff,
+			"state":   smarthometest.ReportState,
+		}
+		return s.sendToEndpoint(builders[cmd](args[1]), args[1])
+	case "send":
+		return s.sendAny(args[1:], line)
+	default:
+		return fmt.Errorf("unknown command %s, type help to list all commands", args[0])
+	}
+}
+
+func (s *simulator) link(email string) error {
+	if s.lwa == nil {
+		lwa, err := smarthometest.NewLWAAt(s.lwaAddr, s.clientID, s.clientSecret)
+		if err != nil {
+			return err
+		}
+
+		s.lwa = lwa
+		fmt.Fprintf(s.out, "fake login with amazon listening, token url %s, profile url %s\n", lwa.TokenURL(), lwa.ProfileURL())
+	}
+
+	code, granteeToken := s.lwa.Grant(common.Identity{Email: email, UserID: "amzn1.account." + email, Name: email})
+
+	resp, err := s.send(smarthometest.AcceptGrant(code, granteeToken))
+	if resp == nil || errors.As(err, &errorResponse{}) {
+		return err
+	}
+
+	if resp.Event.Header == nil || resp.Event.Header.Name != "AcceptGrant.Response" {
+		return fmt.Errorf("account of %s was not linked", email)
+	}
+
+	s.token = granteeToken
+	fmt.Fprintf(s.out, "linked %s, using token %s\n", email, granteeToken)
+
+	return err
+}
+
+func (s *simulator) discover() error {
+	resp, err := s.send(smarthometest.Discover().WithToken(s.token))
+	if resp == nil || errors.As(err, &errorResponse{}) {
+		return err
+	}
+
+	var payload struct {
+		Endpoints []discoverable.Endpoint `json:"endpoints"`
+	}
+
+	data, _ := json.Marshal(resp.Event.Payload)
+	if jsonErr := json.Unmarshal(data, &payload); jsonErr != nil {
+		return fmt.Errorf("could not read discovered endpoints; %v", jsonErr)
+	}
+
+	s.endpoints = payload.Endpoints
+	fmt.Fprintf(s.out, "discovered %d endpoints\n", len(s.endpoints))
+
+	return err
+}
+
+func (s *simulator) listEndpoints() {
+	if len(s.endpoints) == 0 {
+		fmt.Fprintln(s.out, "no endpoints discovered, run discover first")
+		return
+	}
+
+	w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENDPOINT\tNAME\tCOOKIE\tCAPABILITIES")
+
+	for _, ep := range s.endpoints {
+		var interfaces []string
+		for _, c := range ep.Capabilities {
+			if c.Interface != "Alexa" {
+				interfaces = append(interfaces, c.Interface)
+			}
+		}
+		sort.Strings(interfaces)
+
+		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s\n", ep.EndpointID, ep.FriendlyName, ep.Cookie.Type, ep.Cookie.ID, strings.Join(interfaces, ", "))
+	}
+
+	w.Flush()
+}
+
+func (s *simulator) sendAny(args []string, line string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: send <namespace> <name> [endpointId] [payload]")
+	}
+
+	var b *smarthometest.DirectiveBuilder
+	var endpointID string
+
+	if len(args) > 2 && !strings.HasPrefix(args[2], "{") {
+		endpointID = args[2]
+		b = smarthometest.NewEndpointDirective(args[0], args[1], endpointID)
+	} else {
+		b = smarthometest.NewDirective(args[0], args[1])
+	}
+
+	if i := strings.Index(line, "{"); i >= 0 {
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(line[i:]), &payload); err != nil {
+			return fmt.Errorf("invalid payload; %v", err)
+		}
+
+		for k, v := range payload {
+			b.WithPayload(k, v)
+		}
+	}
+
+	if endpointID == "" {
+		b.WithToken(s.token)
+		_, err := s.send(b)
+		return err
+	}
+
+	return s.sendToEndpoint(b, endpointID)
+}
+
+func (s *simulator) sendToEndpoint(b *smarthometest.DirectiveBuilder, endpointID string) error {
+	b.WithToken(s.token)
+
+	for _, ep := range s.endpoints {
+		if ep.EndpointID == endpointID {
+			b.WithCookie(ep.Cookie.Type, ep.Cookie.ID, ep.Cookie.Name)
+		}
+	}
+
+	_, err := s.send(b)
+	return err
+}
+
+// send the directive to the handler, print the response and validate it
+func (s *simulator) send(b *smarthometest.DirectiveBuilder) (*common.Response, error) {
+	b.WithMessageID(newMessageID())
+
+	request, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(b.JSON()))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	if s.username != "" {
+		request.SetBasicAuth(s.username, s.password)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("handler responded with %s; %s", response.Status, strings.TrimSpace(string(body)))
+	}
+
+	var pretty bytes.Buffer
+	if err = json.Indent(&pretty, body, "", "  "); err != nil {
+		return nil, fmt.Errorf("handler responded with invalid json; %v", err)
+	}
+	fmt.Fprintln(s.out, pretty.String())
+
+	resp := new(common.Response)
+	if err = json.Unmarshal(body, resp); err != nil {
+		return nil, fmt.Errorf("could not unmarshal response; %v", err)
+	}
+
+	if s.validator != nil {
+		if err = s.validator.Validate(body); err != nil {
+			return resp, fmt.Errorf("response is invalid;%v", err)
+		}
+		fmt.Fprintln(s.out, "response is valid")
+	}
+
+	if resp.Event.Header != nil && resp.Event.Header.Name == "ErrorResponse" {
+		var payload errorResponse
+
+		data, _ := json.Marshal(resp.Event.Payload)
+		_ = json.Unmarshal(data, &payload)
+
+		return resp, payload
+	}
+
+	return resp, nil
+}
+
+// errorResponse is returned by send if the handler answered with an ErrorResponse
+type errorResponse struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func (e errorResponse) Error() string {
+	return fmt.Sprintf("handler responded with %s; %s", e.Type, e.Message)
+}
+
+func newMessageID() string {
+	return common.NewHeader("", "").MessageID
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+	"github.com/betom84/go-alexa/smarthome/smarthometest"
+	"github.com/betom84/go-alexa/smarthome/validator"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunScript(t *testing.T) {
+	lwa := smarthometest.NewLWA("client-id", "client-secret")
+	defer lwa.Close()
+
+	lamp := smarthometest.NewPowerDevice(false)
+	factory := smarthometest.NewDeviceFactory().AddEndpoint(discoverable.Endpoint{
+		EndpointID:        "lamp-1",
+		FriendlyName:      "Lamp",
+		Description:       "Lamp in the living room",
+		ManufacturerName:  "go-alexa",
+		DisplayCategories: []discoverable.DisplayCategory{discoverable.Light},
+		Cookie:            common.Cookie{Type: "light", ID: "L1"},
+		Capabilities: []discoverable.Capability{
+			discoverable.NewCapability("Alexa.PowerController", []string{"powerState"}),
+		},
+	}, lamp)
+
+	authority := smarthome.Authority{ClientID: "client-id", ClientSecret: "client-secret", Provider: lwa.Provider()}
+	handler := smarthome.NewDefaultHandlerWithSource(authority, factory)
+	handler.DeviceFactory = factory
+	handler.ScopeVerifier = lwa
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	out := new(bytes.Buffer)
+	s := &simulator{
+		url:       srv.URL,
+		client:    srv.Client(),
+		validator: &validator.Validator{},
+		lwa:       lwa,
+		out:       out,
+	}
+
+	script := `
+# link and control the lamp
+link jane@example.com
+discover
+endpoints
+turnon lamp-1
+state lamp-1
+send Alexa.PowerController TurnOff lamp-1 {}
+turnon unknown-lamp
+unknown-command
+quit
+turnon lamp-1
+`
+
+	failed := run(s, strings.NewReader(script))
+	output := out.String()
+
+	assert.Equal(t, 2, failed, output)
+	assert.Contains(t, output, "linked jane@example.com")
+	assert.Contains(t, output, "discovered 1 endpoints")
+	assert.Regexp(t, `lamp-1 +Lamp +light/L1 +Alexa.PowerController`, output)
+	assert.Contains(t, output, `"name": "StateReport"`)
+	assert.Contains(t, output, "response is valid")
+	assert.Contains(t, output, `"type": "NO_SUCH_ENDPOINT"`)
+	assert.Contains(t, output, "error in line 9: handler responded with NO_SUCH_ENDPOINT;")
+	assert.NotContains(t, output, "response is invalid")
+	assert.Contains(t, output, "error in line 10: unknown command unknown-command")
+	assert.False(t, lamp.IsOn(), "lamp should be turned off by the last command before quit")
+	assert.Equal(t, 2, lamp.SetStateCalls())
+}
+
+func TestExecuteUsage(t *testing.T) {
+	s := &simulator{out: new(bytes.Buffer)}
+
+	for _, line := range []string{"link", "token", "turnon", "send Alexa", "send Alexa ReportState lamp-1 {invalid"} {
+		assert.Error(t, s.execute(line), line)
+	}
+
+	assert.NoError(t, s.execute("token abc"))
+	assert.Equal(t, "abc", s.token)
+
+	assert.NoError(t, s.execute("endpoints"))
+	assert.Contains(t, s.out.(*bytes.Buffer).String(), "no endpoints discovered")
+}
+
+func TestRepl(t *testing.T) {
+	out := new(bytes.Buffer)
+	s := &simulator{out: out}
+
+	repl(s, strings.NewReader("help\nturnon\nquit\nhelp\n"))
+
+	assert.Equal(t, 1, strings.Count(out.String(), "commands:"), "commands after quit must not be executed")
+	assert.Contains(t, out.String(), "error: usage: turnon <endpointId>")
+}
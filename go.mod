@@ -1,6 +1,6 @@
 module github.com/betom84/go-alexa
 
-go 1.14
+go 1.16
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
@@ -11,4 +11,5 @@ require (
 	github.com/xeipuuv/gojsonpointer v0.0.0-20180127040702-4e3ac2762d5f // indirect
 	github.com/xeipuuv/gojsonreference v0.0.0-20180127040603-bd5ef7bd5415 // indirect
 	github.com/xeipuuv/gojsonschema v0.0.0-20180207214316-8bcffc811467
+	gopkg.in/yaml.v2 v2.4.0
 )
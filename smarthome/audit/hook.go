@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/betom84/go-alexa/smarthome"
+)
+
+// OnHandled returns a hook for smarthome.Handler.OnHandled, which writes a record of each handled directive to
+// the journal. Errors writing the journal are passed to onError, which is optional, and don't affect the response.
+func OnHandled(journal *Journal, onError func(error)) func(smarthome.HandledDirective) {
+	return func(handled smarthome.HandledDirective) {
+		dir := handled.Directive
+
+		record := Record{
+			Time:      Now(),
+			Namespace: dir.Header.Namespace,
+			Name:      dir.Header.Name,
+			LatencyMS: float64(handled.Took.Microseconds()) / 1000,
+			ErrorType: handled.ErrorType,
+			Error:     handled.Error,
+		}
+
+		if dir.Endpoint != nil {
+			record.EndpointID = dir.Endpoint.EndpointID
+		}
+
+		if dir.Identity != nil {
+			record.User = dir.Identity.Email
+		}
+
+		if handled.Device != nil {
+			record.DeviceType = fmt.Sprintf("%T", handled.Device)
+		}
+
+		record.Directive, _ = json.Marshal(dir)
+
+		if handled.Response != nil {
+			record.Response, _ = json.Marshal(handled.Response)
+		}
+
+		if err := journal.Write(record); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Package audit records processed directives and their responses as JSON Lines, to troubleshoot what alexa
+// asked for and what happened
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/redact"
+)
+
+// Now is used to change the current time for tests, defaults to time.Now()
+var Now = time.Now
+
+// defaultRedactor is used by journals without Redactor, it's created once because it compiles its patterns
+var defaultRedactor = redact.New()
+
+// Record of a processed directive
+type Record struct {
+	Time       time.Time       `json:"time"`
+	Namespace  string          `json:"namespace"`
+	Name       string          `json:"name"`
+	EndpointID string          `json:"endpointId,omitempty"`
+	User       string          `json:"user,omitempty"`
+	DeviceType string          `json:"deviceType,omitempty"`
+	LatencyMS  float64         `json:"latencyMs"`
+	Directive  json.RawMessage `json:"directive"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	ErrorType  string          `json:"errorType,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// Journal appends records to a file, which is rotated when it exceeds the maximum size. Rotated files are
+// named like the file with suffix ".1" (the most recent) to ".<MaxBackups>". It's safe for concurrent use.
+type Journal struct {
+	// Path of the journal file
+	Path string
+
+	// MaxSize of the journal file in bytes before it gets rotated, 0 disables rotation
+	MaxSize int64
+
+	// MaxBackups is the number of rotated files to retain
+	MaxBackups int
+
+	// Redactor masks sensitive values of directives and responses, defaults to redact.New()
+	Redactor *redact.Redactor
+
+	mutex sync.Mutex
+	file  *os.File
+	size  int64
+}
+
+// NewJournal creates a journal writing to path, rotating at maxSize bytes and retaining maxBackups rotated files
+func NewJournal(path string, maxSize int64, maxBackups int) *Journal {
+	return &Journal{Path: path, MaxSize: maxSize, MaxBackups: maxBackups}
+}
+
+// Write appends the record to the journal, directive and response are redacted
+func (j *Journal) Write(record Record) error {
+	redactor := j.Redactor
+	if redactor == nil {
+		redactor = defaultRedactor
+	}
+
+	if len(record.Directive) > 0 {
+		record.Directive = redactor.JSON(record.Directive)
+	}
+
+	if len(record.Response) > 0 {
+		record.Response = redactor.JSON(record.Response)
+	}
+
+	record.Error = redactor.String(record.Error)
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("could not encode record; %v", err)
+	}
+	line = append(line, '\n')
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	// the file is opened first to know the size of an existing journal
+	if j.file == nil {
+		if err = j.open(); err != nil {
+			return err
+		}
+	}
+
+	if j.MaxSize > 0 && j.size > 0 && j.size+int64(len(line)) > j.MaxSize {
+		if err = j.rotate(); err != nil {
+			return err
+		}
+
+		if err = j.open(); err != nil {
+			return err
+		}
+	}
+
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+
+	return err
+}
+
+// Close the journal file
+func (j *Journal) Close() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+
+	return err
+}
+
+func (j *Journal) open() error {
+	file, err := os.OpenFile(j.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("could not open journal; %v", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("could not open journal; %v", err)
+	}
+
+	j.file, j.size = file, info.Size()
+
+	return nil
+}
+
+func (j *Journal) rotate() error {
+	if j.file != nil {
+		_ = j.file.Close()
+		j.file = nil
+	}
+
+	_ = os.Remove(j.backup(j.MaxBackups))
+	for i := j.MaxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(j.backup(i), j.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not rotate journal; %v", err)
+		}
+	}
+
+	var err error
+	if j.MaxBackups > 0 {
+		err = os.Rename(j.Path, j.backup(1))
+	} else {
+		err = os.Remove(j.Path)
+	}
+
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not rotate journal; %v", err)
+	}
+
+	j.size = 0
+
+	return nil
+}
+
+func (j *Journal) backup(i int) string {
+	return fmt.Sprintf("%s.%d", j.Path, i)
+}
+
+// Query selects records, empty fields match all records
+type Query struct {
+	EndpointID string
+
+	// From and To limit the time of records, both are inclusive
+	From time.Time
+	To   time.Time
+}
+
+func (q Query) matches(r Record) bool {
+	return (q.EndpointID == "" || q.EndpointID == r.EndpointID) &&
+		(q.From.IsZero() || !r.Time.Before(q.From)) &&
+		(q.To.IsZero() || !r.Time.After(q.To))
+}
+
+// Read the records of the journal and its rotated files matching the query, ordered from oldest to newest
+func (j *Journal) Read(q Query) ([]Record, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var records []Record
+	for i := j.MaxBackups; i >= 0; i-- {
+		path := j.Path
+		if i > 0 {
+			path = j.backup(i)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("could not read journal; %v", err)
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+
+		for line := 1; scanner.Scan(); line++ {
+			var r Record
+			if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+				return nil, fmt.Errorf("could not decode record %s:%d; %v", path, line, err)
+			}
+
+			if q.matches(r) {
+				records = append(records, r)
+			}
+		}
+	}
+
+	return records, nil
+}
@@ -0,0 +1,264 @@
+package audit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/audit"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/identity"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func createJournal(t *testing.T, maxSize int64, maxBackups int) *audit.Journal {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	journal := audit.NewJournal(filepath.Join(dir, "audit.jsonl"), maxSize, maxBackups)
+	t.Cleanup(func() {
+		_ = journal.Close()
+		_ = os.RemoveAll(dir)
+	})
+
+	return journal
+}
+
+func at(minute int) time.Time {
+	return time.Date(2020, 1, 1, 12, minute, 0, 0, time.UTC)
+}
+
+func TestJournalQuery(t *testing.T) {
+	journal := createJournal(t, 0, 0)
+
+	for i, id := range []string{"appliance-001", "appliance-002", "appliance-001", "appliance-001"} {
+		assert.NoError(t, journal.Write(audit.Record{Time: at(i), Namespace: "Alexa", Name: "ReportState", EndpointID: id, Directive: json.RawMessage(`{}`)}))
+	}
+
+	tt := []struct {
+		name     string
+		query    audit.Query
+		expected []time.Time
+	}{
+		{
+			name:     "it reads all records",
+			expected: []time.Time{at(0), at(1), at(2), at(3)},
+		},
+		{
+			name:     "it reads records of endpoint",
+			query:    audit.Query{EndpointID: "appliance-001"},
+			expected: []time.Time{at(0), at(2), at(3)},
+		},
+		{
+			name:     "it reads records of time range",
+			query:    audit.Query{From: at(1), To: at(2)},
+			expected: []time.Time{at(1), at(2)},
+		},
+		{
+			name:     "it reads records of endpoint and time range",
+			query:    audit.Query{EndpointID: "appliance-001", From: at(1)},
+			expected: []time.Time{at(2), at(3)},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			records, err := journal.Read(tc.query)
+			assert.NoError(t, err)
+
+			var times []time.Time
+			for _, r := range records {
+				times = append(times, r.Time)
+			}
+			assert.Equal(t, tc.expected, times)
+		})
+	}
+}
+
+func TestJournalRotation(t *testing.T) {
+	journal := createJournal(t, 300, 2)
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, journal.Write(audit.Record{Time: at(i), Namespace: "Alexa", Name: "ReportState", Directive: json.RawMessage(`{"header":{}}`)}))
+	}
+
+	for _, path := range []string{journal.Path, journal.Path + ".1", journal.Path + ".2"} {
+		info, err := os.Stat(path)
+		if assert.NoError(t, err) {
+			assert.True(t, info.Size() <= 300, "%s exceeds max size", path)
+		}
+	}
+
+	_, err := os.Stat(journal.Path + ".3")
+	assert.True(t, os.IsNotExist(err), "only 2 backups should be retained")
+
+	records, err := journal.Read(audit.Query{})
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, records) {
+		assert.Equal(t, at(9), records[len(records)-1].Time)
+		assert.True(t, len(records) < 10, "records of deleted backups should be gone")
+	}
+}
+
+func TestJournalRotatesExistingFile(t *testing.T) {
+	journal := createJournal(t, 300, 1)
+
+	existing := bytes.Repeat([]byte(`{"namespace":"Alexa"}`+"\n"), 14)
+	if err := ioutil.WriteFile(journal.Path, existing, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, journal.Write(audit.Record{Time: at(0), Namespace: "Alexa", Name: "ReportState", Directive: json.RawMessage(`{}`)}))
+
+	backup, err := ioutil.ReadFile(journal.Path + ".1")
+	assert.NoError(t, err)
+	assert.Equal(t, existing, backup, "existing journal should be rotated before exceeding max size")
+
+	info, err := os.Stat(journal.Path)
+	if assert.NoError(t, err) {
+		assert.True(t, info.Size() <= 300, "journal exceeds max size")
+	}
+}
+
+func TestJournalRedaction(t *testing.T) {
+	journal := createJournal(t, 0, 0)
+
+	assert.NoError(t, journal.Write(audit.Record{
+		Time:      at(0),
+		Directive: json.RawMessage(`{"endpoint":{"scope":{"type":"BearerToken","token":"Atza|secret"}}}`),
+		Response:  json.RawMessage(`{"event":{"endpoint":{"scope":{"type":"BearerToken","token":"Atza|secret"}}}}`),
+		Error:     "could not connect; Bearer Atza|secret",
+	}))
+
+	data, err := ioutil.ReadFile(journal.Path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "Atza|secret")
+}
+
+func TestOnHandled(t *testing.T) {
+	audit.Now = func() time.Time { return at(0) }
+	defer func() { audit.Now = time.Now }()
+
+	request, err := ioutil.ReadFile("../testdata/process_directive_request.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name               string
+		device             interface{}
+		deviceErr          error
+		processErr         error
+		panics             bool
+		verifier           identity.Verifier
+		expectedDeviceType string
+		expectedErrorType  string
+		expectedError      string
+	}{
+		{
+			name:               "it records processed directives",
+			device:             "device",
+			expectedDeviceType: "string",
+		},
+		{
+			name:               "it records errors of the processor",
+			device:             "device",
+			processErr:         common.NewEndpointUnreachableError("device is offline"),
+			expectedDeviceType: "string",
+			expectedErrorType:  "ENDPOINT_UNREACHABLE",
+			expectedError:      "device is offline",
+		},
+		{
+			name:               "it records payload errors like the handler answers them",
+			device:             "device",
+			processErr:         common.PayloadError{Field: "powerState", Message: "must be of type string"},
+			expectedDeviceType: "string",
+			expectedErrorType:  "INVALID_DIRECTIVE",
+			expectedError:      "invalid payload field powerState; must be of type string",
+		},
+		{
+			name:              "it records errors of the device factory",
+			deviceErr:         smarthome.NewDeviceUnreachableError("device is offline"),
+			expectedErrorType: "ENDPOINT_UNREACHABLE",
+			expectedError:     "device is offline",
+		},
+		{
+			name:              "it records unknown endpoints",
+			deviceErr:         smarthome.NewDeviceNotFoundError("unknown device"),
+			expectedErrorType: "NO_SUCH_ENDPOINT",
+			expectedError:     "unknown device",
+		},
+		{
+			name: "it records directives with invalid scope",
+			verifier: identity.VerifierFunc(func(scope common.Scope) (*common.Identity, error) {
+				return nil, identity.ErrInvalidToken
+			}),
+			expectedErrorType: "INVALID_AUTHORIZATION_CREDENTIAL",
+			expectedError:     "scope token is invalid",
+		},
+		{
+			name:               "it records recovered panics",
+			device:             "device",
+			panics:             true,
+			expectedDeviceType: "string",
+			expectedErrorType:  "INTERNAL_ERROR",
+			expectedError:      "unexpected error while processing directive",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			journal := createJournal(t, 0, 0)
+
+			f := &mocks.MockDeviceFactory{}
+			f.On("NewDevice", "testing", "ABC-123").Return(tc.device, tc.deviceErr)
+
+			p := &mocks.MockDirectiveProcessor{}
+			p.On("IsCapable", mock.Anything).Return(true)
+			if tc.panics {
+				p.On("Process", mock.Anything, "device").Run(func(mock.Arguments) { panic("processor panicked") })
+			} else if tc.processErr != nil {
+				p.On("Process", mock.Anything, "device").Return((*common.Response)(nil), tc.processErr)
+			} else {
+				p.On("Process", mock.Anything, "device").Return(&common.Response{}, nil)
+			}
+
+			handler := smarthome.Handler{DeviceFactory: f, ScopeVerifier: tc.verifier}
+			handler.AddDirectiveProcessor(p)
+			handler.OnHandled = audit.OnHandled(journal, func(err error) { t.Error(err) })
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(request)))
+
+			records, err := journal.Read(audit.Query{EndpointID: "appliance-001"})
+			assert.NoError(t, err)
+
+			if assert.Len(t, records, 1) {
+				record := records[0]
+				assert.Equal(t, at(0), record.Time)
+				assert.Equal(t, "Something.Processable", record.Namespace)
+				assert.Equal(t, "DoIt", record.Name)
+				assert.Equal(t, tc.expectedDeviceType, record.DeviceType)
+				assert.Equal(t, tc.expectedErrorType, record.ErrorType)
+				assert.Equal(t, tc.expectedError, record.Error)
+				assert.Contains(t, string(record.Directive), `"token":"[REDACTED]"`)
+				assert.JSONEq(t, strings.Replace(rec.Body.String(), "access-token-from-skill", "[REDACTED]", -1), string(record.Response),
+					"the response sent to alexa should be recorded")
+			}
+		})
+	}
+}
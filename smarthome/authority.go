@@ -2,6 +2,15 @@ package smarthome
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+	"github.com/betom84/go-alexa/smarthome/directives/authorization"
+	"github.com/betom84/go-alexa/smarthome/directives/discovery"
+	"github.com/betom84/go-alexa/smarthome/gateway"
 )
 
 // Authority to handle alexa user authorization
@@ -14,11 +23,27 @@ type Authority struct {
 
 	// E-Mail addresses of users granted access
 	RestrictedUsers []string
+
+	// Users granted access mapped by e-mail address, in addition to RestrictedUsers. If neither Users nor
+	// RestrictedUsers are specified, all users are granted access.
+	Users map[string]User
+
+	// Tokens of granted users to send events to the alexa event gateway, optional
+	Tokens gateway.TokenStore
+
+	// Provider used for account linking, defaults to login with amazon
+	Provider authorization.IdentityProvider
+}
+
+// User is the configuration of an alexa user granted access
+type User struct {
+	// Endpoints discoverable by the user, used in multi-tenant mode
+	Endpoints discovery.EndpointSource
 }
 
 // AcceptGrant is used to grant access to an alexa user and store the according access tokens
 func (a Authority) AcceptGrant(email string, bearerToken string, accessTokens map[string]interface{}) error {
-	var granted = (len(a.RestrictedUsers) == 0)
+	var granted = (len(a.RestrictedUsers) == 0 && len(a.Users) == 0)
 	for _, restricted := range a.RestrictedUsers {
 		granted = (restricted == email)
 		if granted {
@@ -26,12 +51,23 @@ func (a Authority) AcceptGrant(email string, bearerToken string, accessTokens ma
 		}
 	}
 
+	if _, ok := a.Users[email]; ok {
+		granted = true
+	}
+
 	if !granted {
 		return errors.New("Restricted users only")
 	}
 
-	// todo, hold the token for async responses and refresh after the given time
-	//log.Printf("Granted access to %s.\nAccess tokens:%s", email, accessTokens)
+	if a.Tokens != nil {
+		token := gateway.NewToken(accessTokens, time.Now())
+		token.GranteeToken = bearerToken
+
+		if err := a.Tokens.Store(email, token); err != nil {
+			return err
+		}
+	}
+
 	Log.Info("Granted access to %s.", email)
 
 	return nil
@@ -46,3 +82,44 @@ func (a Authority) GetClientID() string {
 func (a Authority) GetClientSecret() string {
 	return a.ClientSecret
 }
+
+// GetIdentityProvider returns the provider used for account linking
+func (a Authority) GetIdentityProvider() authorization.IdentityProvider {
+	return a.Provider
+}
+
+// NewGateway creates an event gateway client for the given url, which sends events on behalf of all granted users
+func (a Authority) NewGateway(url string) *gateway.Gateway {
+	return &gateway.Gateway{
+		URL:          url,
+		ClientID:     a.ClientID,
+		ClientSecret: a.ClientSecret,
+		Tokens:       a.Tokens,
+	}
+}
+
+// Endpoints returns the endpoints of the user identified by the scope, which must be verified.
+// It's used as endpoint source in multi-tenant mode.
+func (a Authority) Endpoints(scope common.Scope) ([]discoverable.Endpoint, error) {
+	if scope.Identity == nil {
+		return nil, fmt.Errorf("identity of scope is unknown")
+	}
+
+	user, ok := a.Users[scope.Identity.Email]
+	if !ok || user.Endpoints == nil {
+		return []discoverable.Endpoint{}, nil
+	}
+
+	return user.Endpoints.Endpoints(scope)
+}
+
+// UserNames returns the e-mail addresses of all configured users, sorted by name
+func (a Authority) UserNames() []string {
+	names := make([]string, 0, len(a.Users))
+	for name := range a.Users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
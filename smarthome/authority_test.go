@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/gateway"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -21,3 +22,32 @@ func TestAuthority(t *testing.T) {
 	assert.Nil(t, authority.AcceptGrant("somebody@mail.com", "", nil))
 	assert.Errorf(t, authority.AcceptGrant("anybody@mail.com", "", nil), "Restricted users only")
 }
+
+func TestAuthorityStoresTokens(t *testing.T) {
+	store := &gateway.MemoryTokenStore{}
+	authority := smarthome.Authority{
+		ClientID:     "clientID",
+		ClientSecret: "clientSecret",
+		Tokens:       store,
+	}
+
+	err := authority.AcceptGrant("somebody@mail.com", "grantee-token", map[string]interface{}{
+		"access_token":  "Atza|access",
+		"refresh_token": "Atzr|refresh",
+		"token_type":    "bearer",
+		"expires_in":    float64(3600),
+	})
+	assert.NoError(t, err)
+
+	token, err := store.Token("somebody@mail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "Atza|access", token.AccessToken)
+	assert.Equal(t, "Atzr|refresh", token.RefreshToken)
+	assert.Equal(t, "grantee-token", token.GranteeToken)
+
+	g := authority.NewGateway(gateway.Europe)
+	assert.Equal(t, gateway.Europe, g.URL)
+	assert.Equal(t, "clientID", g.ClientID)
+	assert.Equal(t, "clientSecret", g.ClientSecret)
+	assert.Equal(t, store, g.Tokens)
+}
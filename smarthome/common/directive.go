@@ -11,6 +11,9 @@ type Directive struct {
 	Header   *Header                `json:"header"`
 	Endpoint *Endpoint              `json:"endpoint,omitempty"`
 	Payload  map[string]interface{} `json:"payload,omitempty"`
+
+	// Identity of the alexa user the directive was sent for, only set if the scope got verified
+	Identity *Identity `json:"-"`
 }
 
 func (d Directive) String() string {
@@ -35,6 +38,21 @@ func (d Directive) String() string {
 	return buffer.String()
 }
 
+// Scope returns the scope of the directive, which is either part of the endpoint or the payload
+func (d Directive) Scope() (scope Scope, ok bool) {
+	if d.Endpoint != nil {
+		scope, ok = d.Endpoint.Scope, true
+	} else if raw, isMap := d.Payload["scope"].(map[string]interface{}); isMap {
+		scope.Type, _ = raw["type"].(string)
+		scope.Token, _ = raw["token"].(string)
+		ok = true
+	}
+
+	scope.Identity = d.Identity
+
+	return
+}
+
 // NewDirective creates a new directive from json
 func NewDirective(data []byte) (dir *Directive, err error) {
 	dir = new(Directive)
@@ -58,3 +58,44 @@ func TestDirective(t *testing.T) {
 		})
 	}
 }
+
+func TestDirectiveScope(t *testing.T) {
+	tt := []struct {
+		name    string
+		payload string
+		scope   common.Scope
+		ok      bool
+	}{
+		{
+			name:    "it returns scope of endpoint",
+			payload: `{"header":{},"endpoint":{"scope":{"type":"BearerToken","token":"endpoint-token"}},"payload":{"scope":{"type":"BearerToken","token":"payload-token"}}}`,
+			scope:   common.Scope{Type: "BearerToken", Token: "endpoint-token"},
+			ok:      true,
+		},
+		{
+			name:    "it returns scope of payload",
+			payload: `{"header":{},"payload":{"scope":{"type":"BearerToken","token":"payload-token"}}}`,
+			scope:   common.Scope{Type: "BearerToken", Token: "payload-token"},
+			ok:      true,
+		},
+		{
+			name:    "it returns no scope",
+			payload: `{"header":{},"payload":{}}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := common.NewDirective([]byte(tc.payload))
+			assert.NoError(t, err)
+
+			scope, ok := dir.Scope()
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.scope, scope)
+
+			dir.Identity = &common.Identity{Email: "somebody@mail.com"}
+			scope, _ = dir.Scope()
+			assert.Equal(t, dir.Identity, scope.Identity)
+		})
+	}
+}
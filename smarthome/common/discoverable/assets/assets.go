@@ -0,0 +1,88 @@
+// Package assets contains the IDs of the alexa global catalog, used as friendly names of capability resources.
+// Alexa provides the localized names for all supported languages.
+// https://developer.amazon.com/docs/device-apis/resources-and-assets.html#global-alexa-catalog
+package assets
+
+// Actions
+const (
+	ActionsClose = "Alexa.Actions.Close"
+	ActionsLower = "Alexa.Actions.Lower"
+	ActionsOpen  = "Alexa.Actions.Open"
+	ActionsRaise = "Alexa.Actions.Raise"
+)
+
+// Device names
+const (
+	DeviceNameAirPurifier = "Alexa.DeviceName.AirPurifier"
+	DeviceNameFan         = "Alexa.DeviceName.Fan"
+	DeviceNameRouter      = "Alexa.DeviceName.Router"
+	DeviceNameShade       = "Alexa.DeviceName.Shade"
+	DeviceNameShower      = "Alexa.DeviceName.Shower"
+	DeviceNameSpaceHeater = "Alexa.DeviceName.SpaceHeater"
+	DeviceNameWasher      = "Alexa.DeviceName.Washer"
+)
+
+// Settings
+const (
+	Setting2GGuestWiFi      = "Alexa.Setting.2GGuestWiFi"
+	Setting5GGuestWiFi      = "Alexa.Setting.5GGuestWiFi"
+	SettingAuto             = "Alexa.Setting.Auto"
+	SettingDirection        = "Alexa.Setting.Direction"
+	SettingDryCycle         = "Alexa.Setting.DryCycle"
+	SettingFanSpeed         = "Alexa.Setting.FanSpeed"
+	SettingGuestWiFi        = "Alexa.Setting.GuestWiFi"
+	SettingHeat             = "Alexa.Setting.Heat"
+	SettingMode             = "Alexa.Setting.Mode"
+	SettingNight            = "Alexa.Setting.Night"
+	SettingOpening          = "Alexa.Setting.Opening"
+	SettingOscillate        = "Alexa.Setting.Oscillate"
+	SettingPreset           = "Alexa.Setting.Preset"
+	SettingQuiet            = "Alexa.Setting.Quiet"
+	SettingTemperature      = "Alexa.Setting.Temperature"
+	SettingWashCycle        = "Alexa.Setting.WashCycle"
+	SettingWaterTemperature = "Alexa.Setting.WaterTemperature"
+)
+
+// Shower
+const (
+	ShowerHandHeld = "Alexa.Shower.HandHeld"
+	ShowerRainHead = "Alexa.Shower.RainHead"
+)
+
+// Units
+const (
+	UnitAngleDegrees          = "Alexa.Unit.Angle.Degrees"
+	UnitAngleRadians          = "Alexa.Unit.Angle.Radians"
+	UnitDistanceFeet          = "Alexa.Unit.Distance.Feet"
+	UnitDistanceInches        = "Alexa.Unit.Distance.Inches"
+	UnitDistanceKilometers    = "Alexa.Unit.Distance.Kilometers"
+	UnitDistanceMeters        = "Alexa.Unit.Distance.Meters"
+	UnitDistanceMiles         = "Alexa.Unit.Distance.Miles"
+	UnitDistanceYards         = "Alexa.Unit.Distance.Yards"
+	UnitMassGrams             = "Alexa.Unit.Mass.Grams"
+	UnitMassKilograms         = "Alexa.Unit.Mass.Kilograms"
+	UnitPercent               = "Alexa.Unit.Percent"
+	UnitTemperatureCelsius    = "Alexa.Unit.Temperature.Celsius"
+	UnitTemperatureFahrenheit = "Alexa.Unit.Temperature.Fahrenheit"
+	UnitVolumeCubicFeet       = "Alexa.Unit.Volume.CubicFeet"
+	UnitVolumeCubicMeters     = "Alexa.Unit.Volume.CubicMeters"
+	UnitVolumeGallons         = "Alexa.Unit.Volume.Gallons"
+	UnitVolumeLiters          = "Alexa.Unit.Volume.Liters"
+	UnitVolumePints           = "Alexa.Unit.Volume.Pints"
+	UnitVolumeQuarts          = "Alexa.Unit.Volume.Quarts"
+	UnitWeightOunces          = "Alexa.Unit.Weight.Ounces"
+	UnitWeightPounds          = "Alexa.Unit.Weight.Pounds"
+)
+
+// Values
+const (
+	ValueClose     = "Alexa.Value.Close"
+	ValueDelicate  = "Alexa.Value.Delicate"
+	ValueHigh      = "Alexa.Value.High"
+	ValueLow       = "Alexa.Value.Low"
+	ValueMaximum   = "Alexa.Value.Maximum"
+	ValueMedium    = "Alexa.Value.Medium"
+	ValueMinimum   = "Alexa.Value.Minimum"
+	ValueOpen      = "Alexa.Value.Open"
+	ValueQuickWash = "Alexa.Value.QuickWash"
+)
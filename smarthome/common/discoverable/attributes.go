@@ -0,0 +1,28 @@
+package discoverable
+
+// AdditionalAttributes provide information about the device of an endpoint, alexa uses them to identify
+// the device and to improve the user experience
+type AdditionalAttributes struct {
+	Manufacturer     string `json:"manufacturer,omitempty"`
+	Model            string `json:"model,omitempty"`
+	SerialNumber     string `json:"serialNumber,omitempty"`
+	FirmwareVersion  string `json:"firmwareVersion,omitempty"`
+	SoftwareVersion  string `json:"softwareVersion,omitempty"`
+	CustomIdentifier string `json:"customIdentifier,omitempty"`
+}
+
+// Relationship names between endpoints
+const (
+	// IsConnectedBy references the bridge endpoint, the device of an endpoint is connected by
+	IsConnectedBy = "isConnectedBy"
+)
+
+// A Relationship references another endpoint
+type Relationship struct {
+	EndpointID string `json:"endpointId"`
+}
+
+// ConnectedBy creates the relationships of an endpoint connected by the given bridge endpoint
+func ConnectedBy(bridgeEndpointID string) map[string]Relationship {
+	return map[string]Relationship{IsConnectedBy: {EndpointID: bridgeEndpointID}}
+}
@@ -5,8 +5,12 @@ package discoverable
 type Capability struct {
 	Type       string     `json:"type"`
 	Interface  string     `json:"interface"`
+	Instance   string     `json:"instance,omitempty"`
 	Version    string     `json:"version"`
 	Properties Properties `json:"properties"`
+
+	// CapabilityResources hold the friendly names of an instance, needed by Range, Mode and Toggle controllers
+	CapabilityResources *CapabilityResources `json:"capabilityResources,omitempty"`
 }
 
 // Properties of an Capability
@@ -42,3 +46,13 @@ func NewCapability(interfacE string, supportedPropertyNames []string) Capability
 		},
 	}
 }
+
+// NewInstanceCapability to create Capability of an interface with multiple instances (like Range, Mode
+// and Toggle controllers) with default values
+func NewInstanceCapability(interfacE string, instance string, supportedPropertyNames []string, resources *CapabilityResources) Capability {
+	c := NewCapability(interfacE, supportedPropertyNames)
+	c.Instance = instance
+	c.CapabilityResources = resources
+
+	return c
+}
@@ -0,0 +1,244 @@
+package discoverable
+
+// DisplayCategory is used to categorize an endpoint, alexa uses it to show the endpoint in the alexa app
+type DisplayCategory string
+
+// Display categories supported by alexa
+const (
+	// ActivityTrigger describes a combination of devices set to a specific state, when the state change must occur in a specific order
+	ActivityTrigger DisplayCategory = "ACTIVITY_TRIGGER"
+
+	// AirConditioner describes an air conditioner
+	AirConditioner DisplayCategory = "AIR_CONDITIONER"
+
+	// AirFreshener describes an air freshener
+	AirFreshener DisplayCategory = "AIR_FRESHENER"
+
+	// AirPurifier describes an air purifier
+	AirPurifier DisplayCategory = "AIR_PURIFIER"
+
+	// AirQualityMonitor describes an air quality monitor
+	AirQualityMonitor DisplayCategory = "AIR_QUALITY_MONITOR"
+
+	// AlexaVoiceEnabled describes a device that has alexa built in
+	AlexaVoiceEnabled DisplayCategory = "ALEXA_VOICE_ENABLED"
+
+	// AutoAccessory describes a smart device in an automobile
+	AutoAccessory DisplayCategory = "AUTO_ACCESSORY"
+
+	// BluetoothSpeaker describes a speaker connected by bluetooth
+	BluetoothSpeaker DisplayCategory = "BLUETOOTH_SPEAKER"
+
+	// Camera describes a security camera
+	Camera DisplayCategory = "CAMERA"
+
+	// ChristmasTree describes a christmas tree
+	ChristmasTree DisplayCategory = "CHRISTMAS_TREE"
+
+	// CoffeeMaker describes a coffee maker
+	CoffeeMaker DisplayCategory = "COFFEE_MAKER"
+
+	// Computer describes a computer
+	Computer DisplayCategory = "COMPUTER"
+
+	// ContactSensor describes a sensor which detects state changes of contacts
+	ContactSensor DisplayCategory = "CONTACT_SENSOR"
+
+	// Dishwasher describes a dishwasher
+	Dishwasher DisplayCategory = "DISHWASHER"
+
+	// Door describes a door
+	Door DisplayCategory = "DOOR"
+
+	// Doorbell describes a doorbell
+	Doorbell DisplayCategory = "DOORBELL"
+
+	// Dryer describes a clothes dryer
+	Dryer DisplayCategory = "DRYER"
+
+	// ExteriorBlind describes an outdoor window covering like blinds or shutters
+	ExteriorBlind DisplayCategory = "EXTERIOR_BLIND"
+
+	// Fan describes a fan
+	Fan DisplayCategory = "FAN"
+
+	// GameConsole describes a game console
+	GameConsole DisplayCategory = "GAME_CONSOLE"
+
+	// GarageDoor describes a garage door
+	GarageDoor DisplayCategory = "GARAGE_DOOR"
+
+	// Headphones describes wireless headphones
+	Headphones DisplayCategory = "HEADPHONES"
+
+	// Hub describes a hub to connect other devices
+	Hub DisplayCategory = "HUB"
+
+	// InteriorBlind describes an indoor window covering like blinds or curtains
+	InteriorBlind DisplayCategory = "INTERIOR_BLIND"
+
+	// Laptop describes a laptop
+	Laptop DisplayCategory = "LAPTOP"
+
+	// Light describes a light source or fixture
+	Light DisplayCategory = "LIGHT"
+
+	// Microwave describes a microwave oven
+	Microwave DisplayCategory = "MICROWAVE"
+
+	// MobilePhone describes a mobile phone
+	MobilePhone DisplayCategory = "MOBILE_PHONE"
+
+	// MotionSensor describes a sensor which detects motion
+	MotionSensor DisplayCategory = "MOTION_SENSOR"
+
+	// MusicSystem describes a network-connected music system
+	MusicSystem DisplayCategory = "MUSIC_SYSTEM"
+
+	// NetworkHardware describes a network router
+	NetworkHardware DisplayCategory = "NETWORK_HARDWARE"
+
+	// Other describes an endpoint that doesn't belong to any other category
+	Other DisplayCategory = "OTHER"
+
+	// Oven describes an oven cooking appliance
+	Oven DisplayCategory = "OVEN"
+
+	// Phone describes a non-mobile phone
+	Phone DisplayCategory = "PHONE"
+
+	// Printer describes a printer
+	Printer DisplayCategory = "PRINTER"
+
+	// Remote describes a remote control
+	Remote DisplayCategory = "REMOTE"
+
+	// Router describes a network router
+	Router DisplayCategory = "ROUTER"
+
+	// SceneTrigger describes a combination of devices set to a specific state, when the order of the state change is not important
+	SceneTrigger DisplayCategory = "SCENE_TRIGGER"
+
+	// Screen describes a projector screen
+	Screen DisplayCategory = "SCREEN"
+
+	// SecurityPanel describes a security panel
+	SecurityPanel DisplayCategory = "SECURITY_PANEL"
+
+	// SecuritySystem describes a security system
+	SecuritySystem DisplayCategory = "SECURITY_SYSTEM"
+
+	// SlowCooker describes an electric cooking device
+	SlowCooker DisplayCategory = "SLOW_COOKER"
+
+	// Smartlock describes a lock
+	Smartlock DisplayCategory = "SMARTLOCK"
+
+	// Smartplug describes a module plugged into an existing outlet to turn it on and off
+	Smartplug DisplayCategory = "SMARTPLUG"
+
+	// Speaker describes a speaker or speaker system
+	Speaker DisplayCategory = "SPEAKER"
+
+	// StreamingDevice describes a streaming device like apple tv or chromecast
+	StreamingDevice DisplayCategory = "STREAMING_DEVICE"
+
+	// Switch describes an in-wall switch wired to the electrical system
+	Switch DisplayCategory = "SWITCH"
+
+	// Tablet describes a tablet computer
+	Tablet DisplayCategory = "TABLET"
+
+	// TemperatureSensor describes a sensor which reports the temperature
+	TemperatureSensor DisplayCategory = "TEMPERATURE_SENSOR"
+
+	// Thermostat describes a thermostat
+	Thermostat DisplayCategory = "THERMOSTAT"
+
+	// TV describes a television
+	TV DisplayCategory = "TV"
+
+	// VacuumCleaner describes a vacuum cleaner
+	VacuumCleaner DisplayCategory = "VACUUM_CLEANER"
+
+	// Vehicle describes a motor vehicle
+	Vehicle DisplayCategory = "VEHICLE"
+
+	// Washer describes a clothes washer
+	Washer DisplayCategory = "WASHER"
+
+	// WaterHeater describes a water heater
+	WaterHeater DisplayCategory = "WATER_HEATER"
+
+	// Wearable describes a wearable device
+	Wearable DisplayCategory = "WEARABLE"
+
+	// TemperaturSensor describes a sensor which reports the temperature
+	//
+	// Deprecated: use TemperatureSensor instead
+	TemperaturSensor = TemperatureSensor
+)
+
+var displayCategories = map[DisplayCategory]bool{
+	ActivityTrigger:   true,
+	AirConditioner:    true,
+	AirFreshener:      true,
+	AirPurifier:       true,
+	AirQualityMonitor: true,
+	AlexaVoiceEnabled: true,
+	AutoAccessory:     true,
+	BluetoothSpeaker:  true,
+	Camera:            true,
+	ChristmasTree:     true,
+	CoffeeMaker:       true,
+	Computer:          true,
+	ContactSensor:     true,
+	Dishwasher:        true,
+	Door:              true,
+	Doorbell:          true,
+	Dryer:             true,
+	ExteriorBlind:     true,
+	Fan:               true,
+	GameConsole:       true,
+	GarageDoor:        true,
+	Headphones:        true,
+	Hub:               true,
+	InteriorBlind:     true,
+	Laptop:            true,
+	Light:             true,
+	Microwave:         true,
+	MobilePhone:       true,
+	MotionSensor:      true,
+	MusicSystem:       true,
+	NetworkHardware:   true,
+	Other:             true,
+	Oven:              true,
+	Phone:             true,
+	Printer:           true,
+	Remote:            true,
+	Router:            true,
+	SceneTrigger:      true,
+	Screen:            true,
+	SecurityPanel:     true,
+	SecuritySystem:    true,
+	SlowCooker:        true,
+	Smartlock:         true,
+	Smartplug:         true,
+	Speaker:           true,
+	StreamingDevice:   true,
+	Switch:            true,
+	Tablet:            true,
+	TemperatureSensor: true,
+	Thermostat:        true,
+	TV:                true,
+	VacuumCleaner:     true,
+	Vehicle:           true,
+	Washer:            true,
+	WaterHeater:       true,
+	Wearable:          true,
+}
+
+// IsKnown checks if the display category is supported by alexa
+func (c DisplayCategory) IsKnown() bool {
+	return displayCategories[c]
+}
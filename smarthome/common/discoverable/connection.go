@@ -0,0 +1,60 @@
+package discoverable
+
+// ConnectionType describes how a device is connected
+type ConnectionType string
+
+// Connection types supported by alexa
+const (
+	TCPIP   ConnectionType = "TCP_IP"
+	Zigbee  ConnectionType = "ZIGBEE"
+	ZWave   ConnectionType = "ZWAVE"
+	Matter  ConnectionType = "MATTER"
+	UPnP    ConnectionType = "UPNP"
+	Unknown ConnectionType = "UNKNOWN"
+)
+
+// A Connection describes how the device of an endpoint is connected, alexa uses it to avoid
+// duplicate devices discovered by multiple skills or locally by an echo device
+type Connection struct {
+	Type ConnectionType `json:"type"`
+
+	// MacAddress of TCP_IP and ZIGBEE connections
+	MacAddress string `json:"macAddress,omitempty"`
+
+	// HomeID and NodeID of ZWAVE connections
+	HomeID string `json:"homeId,omitempty"`
+	NodeID string `json:"nodeId,omitempty"`
+
+	// MatterDiscriminator, MatterVendorID and MatterProductID of MATTER connections
+	MatterDiscriminator string `json:"matterDiscriminator,omitempty"`
+	MatterVendorID      string `json:"matterVendorId,omitempty"`
+	MatterProductID     string `json:"matterProductId,omitempty"`
+
+	// Value of UPNP (unique device name) and UNKNOWN connections
+	Value string `json:"value,omitempty"`
+}
+
+// NewTCPIPConnection creates a connection of a device connected by TCP/IP with the given MAC address
+func NewTCPIPConnection(macAddress string) Connection {
+	return Connection{Type: TCPIP, MacAddress: macAddress}
+}
+
+// NewZigbeeConnection creates a connection of a zigbee device with the given EUI-64 MAC address
+func NewZigbeeConnection(macAddress string) Connection {
+	return Connection{Type: Zigbee, MacAddress: macAddress}
+}
+
+// NewZWaveConnection creates a connection of a z-wave device
+func NewZWaveConnection(homeID string, nodeID string) Connection {
+	return Connection{Type: ZWave, HomeID: homeID, NodeID: nodeID}
+}
+
+// NewMatterConnection creates a connection of a matter device
+func NewMatterConnection(discriminator string, vendorID string, productID string) Connection {
+	return Connection{Type: Matter, MatterDiscriminator: discriminator, MatterVendorID: vendorID, MatterProductID: productID}
+}
+
+// NewUPnPConnection creates a connection of an UPnP device with the given unique device name
+func NewUPnPConnection(udn string) Connection {
+	return Connection{Type: UPnP, Value: udn}
+}
@@ -0,0 +1,133 @@
+package discoverable
+
+import (
+	"sync"
+
+	"github.com/betom84/go-alexa/smarthome/common/capabilities"
+)
+
+// A Requirement connects an alexa interface with the device capability needed to process its directives
+type Requirement struct {
+	// Interface name, like Alexa.PowerController
+	Interface string
+
+	// Supported property names of the interface
+	Supported []string
+
+	// SatisfiedBy checks if a device is capable to handle the interface
+	SatisfiedBy func(device interface{}) bool
+}
+
+var requirements = struct {
+	sync.RWMutex
+	list []Requirement
+}{
+	list: []Requirement{
+		{
+			Interface: "Alexa.PowerController",
+			Supported: []string{"powerState"},
+			SatisfiedBy: func(device interface{}) bool {
+				_, ok := device.(capabilities.PowerDevice)
+				return ok
+			},
+		},
+		{
+			Interface: "Alexa.TemperatureSensor",
+			Supported: []string{"temperature"},
+			SatisfiedBy: func(device interface{}) bool {
+				_, ok := device.(capabilities.TemperatureSensor)
+				return ok
+			},
+		},
+		{
+			Interface: "Alexa.EndpointHealth",
+			Supported: []string{"connectivity"},
+			SatisfiedBy: func(device interface{}) bool {
+				_, ok := device.(capabilities.HealthConscious)
+				return ok
+			},
+		},
+	},
+}
+
+// RegisterRequirement adds the requirement of a custom interface, an existing requirement for the
+// same interface gets replaced.
+func RegisterRequirement(r Requirement) {
+	requirements.Lock()
+	defer requirements.Unlock()
+
+	for i, existing := range requirements.list {
+		if existing.Interface == r.Interface {
+			requirements.list[i] = r
+			return
+		}
+	}
+
+	requirements.list = append(requirements.list, r)
+}
+
+// RequirementOf returns the requirement registered for the given interface
+func RequirementOf(interfacE string) (Requirement, bool) {
+	requirements.RLock()
+	defer requirements.RUnlock()
+
+	for _, r := range requirements.list {
+		if r.Interface == interfacE {
+			return r, true
+		}
+	}
+
+	return Requirement{}, false
+}
+
+// CapabilitiesOf derives the capabilities from what the given device implements. The list always
+// starts with the mandatory "Alexa" interface.
+func CapabilitiesOf(device interface{}) []Capability {
+	requirements.RLock()
+	defer requirements.RUnlock()
+
+	result := []Capability{NewCapability("Alexa", nil)}
+	for _, r := range requirements.list {
+		if r.SatisfiedBy(device) {
+			result = append(result, NewCapability(r.Interface, r.Supported))
+		}
+	}
+
+	return result
+}
+
+// Unsatisfied returns the declared capabilities the given device doesn't implement. Capabilities of
+// interfaces without registered requirement are expected to be satisfied.
+func Unsatisfied(device interface{}, declared []Capability) []Capability {
+	var result []Capability
+
+	for _, c := range declared {
+		r, ok := RequirementOf(c.Interface)
+		if ok && !r.SatisfiedBy(device) {
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
+
+// Undeclared returns the capabilities derived from the given device which are missing in the declared ones
+func Undeclared(device interface{}, declared []Capability) []Capability {
+	var result []Capability
+
+	for _, c := range CapabilitiesOf(device) {
+		found := false
+		for _, d := range declared {
+			if d.Interface == c.Interface {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,115 @@
+package discoverable_test
+
+import (
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type healthyPowerDevice struct {
+	mocks.MockPowerDevice
+}
+
+func (d *healthyPowerDevice) IsConnected() bool {
+	return true
+}
+
+type colorDevice struct{}
+
+func (d colorDevice) SetColor(hue, saturation, brightness float32) error {
+	return nil
+}
+
+func TestCapabilitiesOf(t *testing.T) {
+	tt := []struct {
+		name       string
+		device     interface{}
+		interfaces []string
+	}{
+		{
+			name:       "it derives alexa interface only for unspecific device",
+			device:     &mocks.MockDevice{},
+			interfaces: []string{"Alexa"},
+		},
+		{
+			name:       "it derives power controller",
+			device:     &mocks.MockPowerDevice{},
+			interfaces: []string{"Alexa", "Alexa.PowerController"},
+		},
+		{
+			name:       "it derives power controller and endpoint health",
+			device:     &healthyPowerDevice{},
+			interfaces: []string{"Alexa", "Alexa.PowerController", "Alexa.EndpointHealth"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var interfaces []string
+			for _, c := range discoverable.CapabilitiesOf(tc.device) {
+				assert.NoError(t, c.Validate())
+				interfaces = append(interfaces, c.Interface)
+			}
+
+			assert.Equal(t, tc.interfaces, interfaces)
+		})
+	}
+
+	caps := discoverable.CapabilitiesOf(&mocks.MockPowerDevice{})
+	assert.Equal(t, discoverable.NewCapability("Alexa.PowerController", []string{"powerState"}), caps[1])
+}
+
+func TestUnsatisfied(t *testing.T) {
+	declared := []discoverable.Capability{
+		discoverable.NewCapability("Alexa", nil),
+		discoverable.NewCapability("Alexa.PowerController", []string{"powerState"}),
+		discoverable.NewCapability("Alexa.TemperatureSensor", []string{"temperature"}),
+		discoverable.NewCapability("Custom.Interface", nil),
+	}
+
+	unsatisfied := discoverable.Unsatisfied(&mocks.MockPowerDevice{}, declared)
+	assert.Equal(t, declared[2:3], unsatisfied)
+
+	assert.Empty(t, discoverable.Unsatisfied(&mocks.MockPowerDevice{}, declared[:2]))
+}
+
+func TestUndeclared(t *testing.T) {
+	declared := []discoverable.Capability{
+		discoverable.NewCapability("Alexa", nil),
+		discoverable.NewCapability("Alexa.EndpointHealth", []string{"connectivity"}),
+	}
+
+	undeclared := discoverable.Undeclared(&healthyPowerDevice{}, declared)
+	assert.Equal(t, []discoverable.Capability{discoverable.NewCapability("Alexa.PowerController", []string{"powerState"})}, undeclared)
+
+	assert.Empty(t, discoverable.Undeclared(&mocks.MockDevice{}, declared))
+}
+
+func TestRegisterRequirement(t *testing.T) {
+	discoverable.RegisterRequirement(discoverable.Requirement{
+		Interface: "Alexa.ColorController",
+		Supported: []string{"color"},
+		SatisfiedBy: func(device interface{}) bool {
+			_, ok := device.(interface {
+				SetColor(hue, saturation, brightness float32) error
+			})
+			return ok
+		},
+	})
+
+	r, ok := discoverable.RequirementOf("Alexa.ColorController")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"color"}, r.Supported)
+
+	caps := discoverable.CapabilitiesOf(colorDevice{})
+	assert.Len(t, caps, 2)
+	assert.Equal(t, "Alexa.ColorController", caps[1].Interface)
+
+	assert.Len(t, discoverable.Unsatisfied(&mocks.MockDevice{}, caps), 1)
+
+	_, ok = discoverable.RequirementOf("Alexa.Unknown")
+	assert.False(t, ok)
+}
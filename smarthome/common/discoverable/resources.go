@@ -0,0 +1,77 @@
+package discoverable
+
+import "sort"
+
+// Types of friendly names
+const (
+	AssetFriendlyName = "asset"
+	TextFriendlyName  = "text"
+)
+
+// CapabilityResources provide the friendly names users refer to an instance of a capability,
+// needed by Range, Mode and Toggle controllers
+type CapabilityResources struct {
+	FriendlyNames []FriendlyName `json:"friendlyNames"`
+}
+
+// A FriendlyName is either an asset ID of the alexa global catalog or a localized text
+type FriendlyName struct {
+	Type  string            `json:"@type"`
+	Value FriendlyNameValue `json:"value"`
+}
+
+// FriendlyNameValue holds the asset ID or the localized text of a friendly name
+type FriendlyNameValue struct {
+	AssetID string `json:"assetId,omitempty"`
+	Text    string `json:"text,omitempty"`
+	Locale  string `json:"locale,omitempty"`
+}
+
+// ResourcesBuilder is used to build capability resources with friendly names in multiple locales
+type ResourcesBuilder struct {
+	names []FriendlyName
+}
+
+// NewResources creates a builder for capability resources
+func NewResources() *ResourcesBuilder {
+	return new(ResourcesBuilder)
+}
+
+// Asset adds friendly names of the alexa global catalog, see package assets
+func (b *ResourcesBuilder) Asset(assetIDs ...string) *ResourcesBuilder {
+	for _, id := range assetIDs {
+		b.names = append(b.names, FriendlyName{Type: AssetFriendlyName, Value: FriendlyNameValue{AssetID: id}})
+	}
+
+	return b
+}
+
+// Text adds a friendly name for the given locale (e.g. en-US)
+func (b *ResourcesBuilder) Text(locale string, text string) *ResourcesBuilder {
+	b.names = append(b.names, FriendlyName{Type: TextFriendlyName, Value: FriendlyNameValue{Text: text, Locale: locale}})
+
+	return b
+}
+
+// Texts adds friendly names in multiple locales, texts are mapped by locale
+func (b *ResourcesBuilder) Texts(texts map[string]string) *ResourcesBuilder {
+	locales := make([]string, 0, len(texts))
+	for locale := range texts {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	for _, locale := range locales {
+		b.Text(locale, texts[locale])
+	}
+
+	return b
+}
+
+// Build the capability resources
+func (b *ResourcesBuilder) Build() *CapabilityResources {
+	names := make([]FriendlyName, len(b.names))
+	copy(names, b.names)
+
+	return &CapabilityResources{FriendlyNames: names}
+}
@@ -0,0 +1,88 @@
+package discoverable_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable/assets"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourcesBuilder(t *testing.T) {
+	resources := discoverable.NewResources().
+		Asset(assets.SettingOpening).
+		Texts(map[string]string{"en-US": "Position", "de-DE": "Position"}).
+		Text("en-US", "Height").
+		Build()
+
+	capability := discoverable.NewInstanceCapability("Alexa.RangeController", "Blind.Lift", []string{"rangeValue"}, resources)
+	assert.NoError(t, capability.Validate())
+
+	marshaled, err := json.Marshal(capability)
+	assert.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"type": "AlexaInterface",
+		"interface": "Alexa.RangeController",
+		"instance": "Blind.Lift",
+		"version": "3",
+		"properties": {"supported": [{"name": "rangeValue"}], "proactivelyReported": false, "retrievable": true},
+		"capabilityResources": {
+			"friendlyNames": [
+				{"@type": "asset", "value": {"assetId": "Alexa.Setting.Opening"}},
+				{"@type": "text", "value": {"text": "Position", "locale": "de-DE"}},
+				{"@type": "text", "value": {"text": "Position", "locale": "en-US"}},
+				{"@type": "text", "value": {"text": "Height", "locale": "en-US"}}
+			]
+		}
+	}`, string(marshaled))
+}
+
+func TestCapabilityResourcesValidate(t *testing.T) {
+	tt := []struct {
+		name        string
+		capability  discoverable.Capability
+		expectError string
+	}{
+		{
+			name:        "it rejects range controller without instance",
+			capability:  discoverable.NewInstanceCapability("Alexa.RangeController", "", []string{"rangeValue"}, discoverable.NewResources().Asset(assets.SettingOpening).Build()),
+			expectError: "instance and capabilityResources of Alexa.RangeController must not be empty",
+		},
+		{
+			name:        "it rejects toggle controller without resources",
+			capability:  discoverable.NewInstanceCapability("Alexa.ToggleController", "Fan.Oscillate", []string{"toggleState"}, nil),
+			expectError: "instance and capabilityResources of Alexa.ToggleController must not be empty",
+		},
+		{
+			name:        "it rejects resources without friendly names",
+			capability:  discoverable.NewInstanceCapability("Alexa.ModeController", "Wash.Cycle", []string{"mode"}, discoverable.NewResources().Build()),
+			expectError: "capabilityResources of Alexa.ModeController: friendlyNames must not be empty",
+		},
+		{
+			name:        "it rejects text without locale",
+			capability:  discoverable.NewInstanceCapability("Alexa.ModeController", "Wash.Cycle", []string{"mode"}, discoverable.NewResources().Text("", "Cycle").Build()),
+			expectError: "capabilityResources of Alexa.ModeController: friendlyNames[0]: locale must not be empty",
+		},
+		{
+			name:        "it rejects empty asset id",
+			capability:  discoverable.NewInstanceCapability("Alexa.ModeController", "Wash.Cycle", []string{"mode"}, discoverable.NewResources().Asset("").Build()),
+			expectError: "capabilityResources of Alexa.ModeController: friendlyNames[0]: assetId must not be empty",
+		},
+		{
+			name: "it rejects unknown friendly name type",
+			capability: discoverable.NewInstanceCapability("Alexa.ModeController", "Wash.Cycle", []string{"mode"}, &discoverable.CapabilityResources{
+				FriendlyNames: []discoverable.FriendlyName{{Type: "image"}},
+			}),
+			expectError: "capabilityResources of Alexa.ModeController: friendlyNames[0]: type image is unknown",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.capability.Validate(), tc.expectError)
+		})
+	}
+}
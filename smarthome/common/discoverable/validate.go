@@ -0,0 +1,217 @@
+package discoverable
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// interfaces which need an instance and capability resources
+var instanceInterfaces = map[string]bool{
+	"Alexa.RangeController":  true,
+	"Alexa.ModeController":   true,
+	"Alexa.ToggleController": true,
+}
+
+var (
+	endpointIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_\-=#;:?@&]*$`)
+	mac48Pattern      = regexp.MustCompile(`^([0-9A-Fa-f]{2}[:-]){5}[0-9A-Fa-f]{2}$`)
+	mac64Pattern      = regexp.MustCompile(`^([0-9A-Fa-f]{2}[:-]){7}[0-9A-Fa-f]{2}$`)
+	hexPattern        = regexp.MustCompile(`^(0x)?[0-9A-Fa-f]+$`)
+)
+
+// Validate checks the endpoint against the constraints defined by alexa
+func (e Endpoint) Validate() error {
+	if e.EndpointID == "" {
+		return fmt.Errorf("endpointId must not be empty")
+	}
+
+	if !endpointIDPattern.MatchString(e.EndpointID) {
+		return fmt.Errorf("endpointId contains invalid characters")
+	}
+
+	for _, f := range []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"endpointId", e.EndpointID, 256},
+		{"friendlyName", e.FriendlyName, 128},
+		{"description", e.Description, 128},
+		{"manufacturerName", e.ManufacturerName, 128},
+	} {
+		if err := validateLength(f.name, f.value, f.max); err != nil {
+			return err
+		}
+	}
+
+	if len(e.DisplayCategories) == 0 {
+		return fmt.Errorf("displayCategories must not be empty")
+	}
+
+	for i, c := range e.DisplayCategories {
+		if !c.IsKnown() {
+			return fmt.Errorf("displayCategories[%d]: %s is unknown", i, c)
+		}
+	}
+
+	if len(e.Capabilities) == 0 {
+		return fmt.Errorf("capabilities must not be empty")
+	}
+
+	for i, c := range e.Capabilities {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("capabilities[%d]: %v", i, err)
+		}
+	}
+
+	if e.AdditionalAttributes != nil {
+		if err := e.AdditionalAttributes.Validate(); err != nil {
+			return fmt.Errorf("additionalAttributes: %v", err)
+		}
+	}
+
+	for i, c := range e.Connections {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("connections[%d]: %v", i, err)
+		}
+	}
+
+	for name, r := range e.Relationships {
+		if r.EndpointID == "" || !endpointIDPattern.MatchString(r.EndpointID) {
+			return fmt.Errorf("relationships.%s: endpointId is invalid", name)
+		}
+
+		if r.EndpointID == e.EndpointID {
+			return fmt.Errorf("relationships.%s: endpoint must not reference itself", name)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the additional attributes against the constraints defined by alexa
+func (a AdditionalAttributes) Validate() error {
+	for _, f := range []struct {
+		name  string
+		value string
+	}{
+		{"manufacturer", a.Manufacturer},
+		{"model", a.Model},
+		{"serialNumber", a.SerialNumber},
+		{"firmwareVersion", a.FirmwareVersion},
+		{"softwareVersion", a.SoftwareVersion},
+		{"customIdentifier", a.CustomIdentifier},
+	} {
+		if f.value == "" {
+			continue
+		}
+
+		if err := validateLength(f.name, f.value, 256); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the connection against the constraints defined by alexa
+func (c Connection) Validate() error {
+	switch c.Type {
+	case TCPIP:
+		if !mac48Pattern.MatchString(c.MacAddress) && !mac64Pattern.MatchString(c.MacAddress) {
+			return fmt.Errorf("macAddress of %s connection is invalid", c.Type)
+		}
+	case Zigbee:
+		if !mac64Pattern.MatchString(c.MacAddress) {
+			return fmt.Errorf("macAddress of %s connection must be an EUI-64 address", c.Type)
+		}
+	case ZWave:
+		if !hexPattern.MatchString(c.HomeID) || !hexPattern.MatchString(c.NodeID) {
+			return fmt.Errorf("homeId and nodeId of %s connection must be hexadecimal", c.Type)
+		}
+	case Matter:
+		if c.MatterDiscriminator == "" || c.MatterVendorID == "" || c.MatterProductID == "" {
+			return fmt.Errorf("matterDiscriminator, matterVendorId and matterProductId of %s connection must not be empty", c.Type)
+		}
+	case UPnP, Unknown:
+		return validateLength("value", c.Value, 256)
+	default:
+		return fmt.Errorf("type %s is unknown", c.Type)
+	}
+
+	return nil
+}
+
+// Validate checks the capability against the constraints defined by alexa
+func (c Capability) Validate() error {
+	if c.Type != "AlexaInterface" {
+		return fmt.Errorf("type must be AlexaInterface")
+	}
+
+	if c.Interface == "" {
+		return fmt.Errorf("interface must not be empty")
+	}
+
+	if c.Version == "" {
+		return fmt.Errorf("version of %s must not be empty", c.Interface)
+	}
+
+	for i, s := range c.Properties.Supported {
+		if s.Name == "" {
+			return fmt.Errorf("supported[%d] of %s must not be empty", i, c.Interface)
+		}
+	}
+
+	if instanceInterfaces[c.Interface] && (c.Instance == "" || c.CapabilityResources == nil) {
+		return fmt.Errorf("instance and capabilityResources of %s must not be empty", c.Interface)
+	}
+
+	if c.CapabilityResources != nil {
+		if err := c.CapabilityResources.Validate(); err != nil {
+			return fmt.Errorf("capabilityResources of %s: %v", c.Interface, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the capability resources against the constraints defined by alexa
+func (r CapabilityResources) Validate() error {
+	if len(r.FriendlyNames) == 0 {
+		return fmt.Errorf("friendlyNames must not be empty")
+	}
+
+	for i, n := range r.FriendlyNames {
+		switch n.Type {
+		case AssetFriendlyName:
+			if n.Value.AssetID == "" {
+				return fmt.Errorf("friendlyNames[%d]: assetId must not be empty", i)
+			}
+		case TextFriendlyName:
+			if n.Value.Locale == "" {
+				return fmt.Errorf("friendlyNames[%d]: locale must not be empty", i)
+			}
+
+			if err := validateLength("text", n.Value.Text, 128); err != nil {
+				return fmt.Errorf("friendlyNames[%d]: %v", i, err)
+			}
+		default:
+			return fmt.Errorf("friendlyNames[%d]: type %s is unknown", i, n.Type)
+		}
+	}
+
+	return nil
+}
+
+func validateLength(name string, value string, max int) error {
+	if value == "" {
+		return fmt.Errorf("%s must not be empty", name)
+	}
+
+	if utf8.RuneCountInString(value) > max {
+		return fmt.Errorf("%s must not be longer than %d characters", name, max)
+	}
+
+	return nil
+}
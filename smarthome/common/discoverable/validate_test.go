@@ -0,0 +1,207 @@
+package discoverable_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointValidate(t *testing.T) {
+	tt := []struct {
+		name        string
+		modify      func(*discoverable.Endpoint)
+		expectError string
+	}{
+		{
+			name:   "it accepts a valid endpoint",
+			modify: func(e *discoverable.Endpoint) {},
+		},
+		{
+			name:        "it rejects empty endpoint id",
+			modify:      func(e *discoverable.Endpoint) { e.EndpointID = "" },
+			expectError: "endpointId must not be empty",
+		},
+		{
+			name:        "it rejects endpoint id with invalid characters",
+			modify:      func(e *discoverable.Endpoint) { e.EndpointID = "light 01" },
+			expectError: "endpointId contains invalid characters",
+		},
+		{
+			name:        "it rejects too long endpoint id",
+			modify:      func(e *discoverable.Endpoint) { e.EndpointID = strings.Repeat("a", 257) },
+			expectError: "endpointId must not be longer than 256 characters",
+		},
+		{
+			name:        "it rejects empty friendly name",
+			modify:      func(e *discoverable.Endpoint) { e.FriendlyName = "" },
+			expectError: "friendlyName must not be empty",
+		},
+		{
+			name:        "it rejects too long description",
+			modify:      func(e *discoverable.Endpoint) { e.Description = strings.Repeat("ä", 129) },
+			expectError: "description must not be longer than 128 characters",
+		},
+		{
+			name:        "it rejects empty manufacturer name",
+			modify:      func(e *discoverable.Endpoint) { e.ManufacturerName = "" },
+			expectError: "manufacturerName must not be empty",
+		},
+		{
+			name:        "it rejects endpoint without display categories",
+			modify:      func(e *discoverable.Endpoint) { e.DisplayCategories = nil },
+			expectError: "displayCategories must not be empty",
+		},
+		{
+			name:        "it rejects unknown display category",
+			modify:      func(e *discoverable.Endpoint) { e.DisplayCategories = append(e.DisplayCategories, "LAMP") },
+			expectError: "displayCategories[1]: LAMP is unknown",
+		},
+		{
+			name:        "it rejects endpoint without capabilities",
+			modify:      func(e *discoverable.Endpoint) { e.Capabilities = nil },
+			expectError: "capabilities must not be empty",
+		},
+		{
+			name:        "it rejects capability without interface",
+			modify:      func(e *discoverable.Endpoint) { e.Capabilities[0].Interface = "" },
+			expectError: "capabilities[0]: interface must not be empty",
+		},
+		{
+			name:        "it rejects capability with unknown type",
+			modify:      func(e *discoverable.Endpoint) { e.Capabilities[0].Type = "Interface" },
+			expectError: "capabilities[0]: type must be AlexaInterface",
+		},
+		{
+			name:        "it rejects capability without version",
+			modify:      func(e *discoverable.Endpoint) { e.Capabilities[0].Version = "" },
+			expectError: "capabilities[0]: version of Alexa.PowerController must not be empty",
+		},
+		{
+			name:        "it rejects capability with empty supported property",
+			modify:      func(e *discoverable.Endpoint) { e.Capabilities[0].Properties.Supported[0].Name = "" },
+			expectError: "capabilities[0]: supported[0] of Alexa.PowerController must not be empty",
+		},
+		{
+			name: "it accepts additional attributes, connections and relationships",
+			modify: func(e *discoverable.Endpoint) {
+				e.AdditionalAttributes = &discoverable.AdditionalAttributes{Manufacturer: "eQ-3", Model: "HM-LC-Sw1-FM", FirmwareVersion: "2.8"}
+				e.Connections = []discoverable.Connection{
+					discoverable.NewTCPIPConnection("00:11:22:AA:BB:33"),
+					discoverable.NewZigbeeConnection("00:11:22:33:44:55:66:77"),
+					discoverable.NewZWaveConnection("847F9532", "0x12"),
+					discoverable.NewMatterConnection("3840", "65521", "32768"),
+					discoverable.NewUPnPConnection("uuid:4d696e69-444c-164e-9d41-b827eb54e3ec"),
+				}
+				e.Relationships = discoverable.ConnectedBy("bridge-01")
+			},
+		},
+		{
+			name: "it rejects too long additional attribute",
+			modify: func(e *discoverable.Endpoint) {
+				e.AdditionalAttributes = &discoverable.AdditionalAttributes{SerialNumber: strings.Repeat("1", 257)}
+			},
+			expectError: "additionalAttributes: serialNumber must not be longer than 256 characters",
+		},
+		{
+			name: "it rejects invalid mac address",
+			modify: func(e *discoverable.Endpoint) {
+				e.Connections = []discoverable.Connection{discoverable.NewTCPIPConnection("00:11:22")}
+			},
+			expectError: "connections[0]: macAddress of TCP_IP connection is invalid",
+		},
+		{
+			name: "it rejects zigbee connection without EUI-64 address",
+			modify: func(e *discoverable.Endpoint) {
+				e.Connections = []discoverable.Connection{discoverable.NewZigbeeConnection("00:11:22:AA:BB:33")}
+			},
+			expectError: "connections[0]: macAddress of ZIGBEE connection must be an EUI-64 address",
+		},
+		{
+			name: "it rejects z-wave connection with invalid node id",
+			modify: func(e *discoverable.Endpoint) {
+				e.Connections = []discoverable.Connection{discoverable.NewZWaveConnection("847F9532", "")}
+			},
+			expectError: "connections[0]: homeId and nodeId of ZWAVE connection must be hexadecimal",
+		},
+		{
+			name: "it rejects incomplete matter connection",
+			modify: func(e *discoverable.Endpoint) {
+				e.Connections = []discoverable.Connection{discoverable.NewMatterConnection("3840", "", "32768")}
+			},
+			expectError: "connections[0]: matterDiscriminator, matterVendorId and matterProductId of MATTER connection must not be empty",
+		},
+		{
+			name: "it rejects upnp connection without value",
+			modify: func(e *discoverable.Endpoint) {
+				e.Connections = []discoverable.Connection{discoverable.NewUPnPConnection("")}
+			},
+			expectError: "connections[0]: value must not be empty",
+		},
+		{
+			name:        "it rejects unknown connection type",
+			modify:      func(e *discoverable.Endpoint) { e.Connections = []discoverable.Connection{{Type: "BLUETOOTH"}} },
+			expectError: "connections[0]: type BLUETOOTH is unknown",
+		},
+		{
+			name:        "it rejects relationship to itself",
+			modify:      func(e *discoverable.Endpoint) { e.Relationships = discoverable.ConnectedBy("light-01") },
+			expectError: "relationships.isConnectedBy: endpoint must not reference itself",
+		},
+		{
+			name:        "it rejects relationship without endpoint id",
+			modify:      func(e *discoverable.Endpoint) { e.Relationships = discoverable.ConnectedBy("") },
+			expectError: "relationships.isConnectedBy: endpointId is invalid",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := discoverable.Endpoint{
+				EndpointID:        "light-01",
+				FriendlyName:      "Kitchen",
+				Description:       "Light in the kitchen",
+				ManufacturerName:  "TDD Inc.",
+				DisplayCategories: []discoverable.DisplayCategory{discoverable.Light},
+				Capabilities: []discoverable.Capability{
+					discoverable.NewCapability("Alexa.PowerController", []string{"powerState"}),
+				},
+			}
+
+			tc.modify(&endpoint)
+
+			err := endpoint.Validate()
+			if len(tc.expectError) > 0 {
+				assert.EqualError(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDisplayCategory(t *testing.T) {
+	for _, c := range []discoverable.DisplayCategory{
+		discoverable.Light,
+		discoverable.Thermostat,
+		discoverable.Smartlock,
+		discoverable.ContactSensor,
+		discoverable.MotionSensor,
+		discoverable.InteriorBlind,
+		discoverable.ExteriorBlind,
+		discoverable.Doorbell,
+		discoverable.Camera,
+		discoverable.TV,
+		discoverable.Speaker,
+		discoverable.Fan,
+		discoverable.TemperatureSensor,
+	} {
+		assert.True(t, c.IsKnown(), "%s should be known", c)
+	}
+
+	assert.Equal(t, discoverable.TemperatureSensor, discoverable.TemperaturSensor)
+	assert.False(t, discoverable.DisplayCategory("LAMP").IsKnown())
+	assert.False(t, discoverable.DisplayCategory("light").IsKnown())
+}
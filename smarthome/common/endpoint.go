@@ -2,10 +2,17 @@ package common
 
 // An Endpoint object identifies the target for a directive and the origin of an event.
 type Endpoint struct {
-	Scope struct {
-		Type  string `json:"type"`
-		Token string `json:"token"`
-	} `json:"scope"`
+	Scope      Scope  `json:"scope"`
 	EndpointID string `json:"endpointId"`
 	Cookie     Cookie `json:"cookie"`
 }
+
+// A Scope provides the authorization and identifying information of the alexa user
+// a directive was sent for.
+type Scope struct {
+	Type  string `json:"type"`
+	Token string `json:"token"`
+
+	// Identity of the user the token belongs to, only set if the token got verified
+	Identity *Identity `json:"-"`
+}
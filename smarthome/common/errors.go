@@ -29,3 +29,31 @@ func NewInternalError(message string) AlexaError {
 func NewAcceptGrantFailedError(message string) AlexaError {
 	return AlexaError{"ACCEPT_GRANT_FAILED", message, "Alexa.Authorization"}
 }
+
+// NewInvalidAuthorizationCredentialError creates an AlexaError to indicate that the authorization credential
+// provided by alexa is invalid. For example, the OAuth2 access token is not valid for the user's account.
+func NewInvalidAuthorizationCredentialError(message string) AlexaError {
+	return AlexaError{"INVALID_AUTHORIZATION_CREDENTIAL", message, "Alexa"}
+}
+
+// NewNoSuchEndpointError creates an AlexaError to indicate that the endpoint does not exist or no longer exists.
+func NewNoSuchEndpointError(message string) AlexaError {
+	return AlexaError{"NO_SUCH_ENDPOINT", message, "Alexa"}
+}
+
+// NewEndpointUnreachableError creates an AlexaError to indicate that the endpoint is unreachable or offline.
+func NewEndpointUnreachableError(message string) AlexaError {
+	return AlexaError{"ENDPOINT_UNREACHABLE", message, "Alexa"}
+}
+
+// NewEndpointLowPowerError creates an AlexaError to indicate that the endpoint can't handle the directive
+// because it's in a low power state.
+func NewEndpointLowPowerError(message string) AlexaError {
+	return AlexaError{"ENDPOINT_LOW_POWER", message, "Alexa"}
+}
+
+// NewBridgeUnreachableError creates an AlexaError to indicate that the bridge connecting the endpoint is
+// unreachable or offline.
+func NewBridgeUnreachableError(message string) AlexaError {
+	return AlexaError{"BRIDGE_UNREACHABLE", message, "Alexa"}
+}
@@ -37,6 +37,41 @@ func TestFactory(t *testing.T) {
 			errType: "INVALID_DIRECTIVE",
 			errNS:   "Alexa",
 		},
+		{
+			name:    "it creates 'invalid authorization credential' error",
+			err:     common.NewInvalidAuthorizationCredentialError("message for test"),
+			errMsg:  "message for test",
+			errType: "INVALID_AUTHORIZATION_CREDENTIAL",
+			errNS:   "Alexa",
+		},
+		{
+			name:    "it creates 'no such endpoint' error",
+			err:     common.NewNoSuchEndpointError("message for test"),
+			errMsg:  "message for test",
+			errType: "NO_SUCH_ENDPOINT",
+			errNS:   "Alexa",
+		},
+		{
+			name:    "it creates 'endpoint unreachable' error",
+			err:     common.NewEndpointUnreachableError("message for test"),
+			errMsg:  "message for test",
+			errType: "ENDPOINT_UNREACHABLE",
+			errNS:   "Alexa",
+		},
+		{
+			name:    "it creates 'endpoint low power' error",
+			err:     common.NewEndpointLowPowerError("message for test"),
+			errMsg:  "message for test",
+			errType: "ENDPOINT_LOW_POWER",
+			errNS:   "Alexa",
+		},
+		{
+			name:    "it creates 'bridge unreachable' error",
+			err:     common.NewBridgeUnreachableError("message for test"),
+			errMsg:  "message for test",
+			errType: "BRIDGE_UNREACHABLE",
+			errNS:   "Alexa",
+		},
 	}
 
 	for _, tc := range tt {
@@ -0,0 +1,13 @@
+package common
+
+// Identity of an alexa user, resolved from the bearer token of a scope
+type Identity struct {
+	// Email address of the user, it identifies the user within this library
+	Email string
+
+	// UserID of the user at the identity provider
+	UserID string
+
+	// Name of the user
+	Name string
+}
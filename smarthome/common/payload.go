@@ -0,0 +1,121 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PayloadError indicates a malformed or invalid directive payload, it's answered with INVALID_DIRECTIVE
+type PayloadError struct {
+	// Field path of the invalid field, like "grant.code", empty if the payload itself is invalid
+	Field   string
+	Message string
+}
+
+func (e PayloadError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid payload; %s", e.Message)
+	}
+
+	return fmt.Sprintf("invalid payload field %s; %s", e.Field, e.Message)
+}
+
+// AlexaError returns the INVALID_DIRECTIVE error to respond with
+func (e PayloadError) AlexaError() AlexaError {
+	return NewInvalidDirectiveError(e.Error())
+}
+
+// PayloadValidator is implemented by payloads to be validated after decoding
+type PayloadValidator interface {
+	Validate() error
+}
+
+// DecodePayload decodes the payload into the struct pointed to by v and validates it if v implements
+// PayloadValidator. Errors are of type PayloadError.
+func (d Directive) DecodePayload(v interface{}) error {
+	data, err := json.Marshal(d.Payload)
+	if err != nil {
+		return PayloadError{Message: err.Error()}
+	}
+
+	err = json.Unmarshal(data, v)
+	if _, ok := err.(PayloadError); ok {
+		return err
+	} else if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+		return PayloadError{Field: typeErr.Field, Message: fmt.Sprintf("must be of type %s", typeErr.Type)}
+	} else if err != nil {
+		return PayloadError{Message: err.Error()}
+	}
+
+	if validator, ok := v.(PayloadValidator); ok {
+		err = validator.Validate()
+		if _, ok := err.(PayloadError); !ok && err != nil {
+			err = PayloadError{Message: err.Error()}
+		}
+	}
+
+	return err
+}
+
+// Grant of an AcceptGrant directive
+type Grant struct {
+	Type string `json:"type"`
+	Code string `json:"code"`
+}
+
+// AcceptGrantPayload is the payload of Alexa.Authorization.AcceptGrant directives
+type AcceptGrantPayload struct {
+	Grant   Grant `json:"grant"`
+	Grantee Scope `json:"grantee"`
+}
+
+// Validate the grant code and grantee token
+func (p AcceptGrantPayload) Validate() error {
+	switch {
+	case p.Grant.Type != "OAuth2.AuthorizationCode":
+		return PayloadError{"grant.type", "must be OAuth2.AuthorizationCode"}
+	case p.Grant.Code == "":
+		return PayloadError{"grant.code", "is missing"}
+	case p.Grantee.Type != "BearerToken":
+		return PayloadError{"grantee.type", "must be BearerToken"}
+	case p.Grantee.Token == "":
+		return PayloadError{"grantee.token", "is missing"}
+	}
+
+	return nil
+}
+
+// DiscoverPayload is the payload of Alexa.Discovery.Discover directives
+type DiscoverPayload struct {
+	Scope Scope `json:"scope"`
+}
+
+// Validate the scope
+func (p DiscoverPayload) Validate() error {
+	switch {
+	case p.Scope.Type != "BearerToken":
+		return PayloadError{"scope.type", "must be BearerToken"}
+	case p.Scope.Token == "":
+		return PayloadError{"scope.token", "is missing"}
+	}
+
+	return nil
+}
+
+// EmptyPayload is the payload of directives without parameters, like Alexa.PowerController.TurnOn,
+// Alexa.PowerController.TurnOff and Alexa.ReportState. Payloads containing any field are rejected.
+type EmptyPayload struct{}
+
+// UnmarshalJSON accepts an empty object or null only
+func (p *EmptyPayload) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return PayloadError{Message: "must be an object"}
+	}
+
+	for field := range fields {
+		return PayloadError{Field: field, Message: "is not expected"}
+	}
+
+	return nil
+}
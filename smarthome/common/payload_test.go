@@ -0,0 +1,107 @@
+package common_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type validatedPayload struct {
+	Value int `json:"value"`
+}
+
+func (p validatedPayload) Validate() error {
+	if p.Value < 0 {
+		return errors.New("value must not be negative")
+	}
+
+	return nil
+}
+
+func TestDecodePayload(t *testing.T) {
+	tt := []struct {
+		name        string
+		payload     map[string]interface{}
+		target      interface{}
+		expected    interface{}
+		expectError string
+	}{
+		{
+			name: "it decodes accept grant payload",
+			payload: map[string]interface{}{
+				"grant":   map[string]interface{}{"type": "OAuth2.AuthorizationCode", "code": "code"},
+				"grantee": map[string]interface{}{"type": "BearerToken", "token": "token"},
+			},
+			target:   &common.AcceptGrantPayload{},
+			expected: &common.AcceptGrantPayload{Grant: common.Grant{Type: "OAuth2.AuthorizationCode", Code: "code"}, Grantee: common.Scope{Type: "BearerToken", Token: "token"}},
+		},
+		{
+			name:        "it validates accept grant payload",
+			payload:     map[string]interface{}{"grant": map[string]interface{}{"type": "OAuth2.AuthorizationCode"}},
+			target:      &common.AcceptGrantPayload{},
+			expectError: "invalid payload field grant.code; is missing",
+		},
+		{
+			name:     "it decodes discover payload",
+			payload:  map[string]interface{}{"scope": map[string]interface{}{"type": "BearerToken", "token": "token"}},
+			target:   &common.DiscoverPayload{},
+			expected: &common.DiscoverPayload{Scope: common.Scope{Type: "BearerToken", Token: "token"}},
+		},
+		{
+			name:        "it validates discover payload",
+			payload:     map[string]interface{}{"scope": map[string]interface{}{"type": "BearerToken"}},
+			target:      &common.DiscoverPayload{},
+			expectError: "invalid payload field scope.token; is missing",
+		},
+		{
+			name:        "it reports fields of wrong type",
+			payload:     map[string]interface{}{"scope": map[string]interface{}{"type": 1}},
+			target:      &common.DiscoverPayload{},
+			expectError: "invalid payload field scope.type; must be of type string",
+		},
+		{
+			name:     "it decodes empty payload",
+			payload:  nil,
+			target:   &common.EmptyPayload{},
+			expected: &common.EmptyPayload{},
+		},
+		{
+			name:     "it decodes empty object as empty payload",
+			payload:  map[string]interface{}{},
+			target:   &common.EmptyPayload{},
+			expected: &common.EmptyPayload{},
+		},
+		{
+			name:        "it rejects fields of empty payload",
+			payload:     map[string]interface{}{"powerState": "ON"},
+			target:      &common.EmptyPayload{},
+			expectError: "invalid payload field powerState; is not expected",
+		},
+		{
+			name:        "it wraps errors of custom validators",
+			payload:     map[string]interface{}{"value": -1},
+			target:      &validatedPayload{},
+			expectError: "invalid payload; value must not be negative",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := common.Directive{Payload: tc.payload}
+
+			err := dir.DecodePayload(tc.target)
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+				assert.IsType(t, common.PayloadError{}, err)
+				assert.Equal(t, "INVALID_DIRECTIVE", err.(common.PayloadError).AlexaError().Type)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, tc.target)
+		})
+	}
+}
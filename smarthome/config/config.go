@@ -0,0 +1,215 @@
+// Package config loads discoverable endpoints from YAML or JSON configuration files
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the structure of a configuration file
+type Config struct {
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// Endpoint is the configuration of a discoverable.Endpoint
+type Endpoint struct {
+	EndpointID        string                         `json:"endpointId"`
+	FriendlyName      string                         `json:"friendlyName"`
+	Description       string                         `json:"description"`
+	ManufacturerName  string                         `json:"manufacturerName"`
+	DisplayCategories []discoverable.DisplayCategory `json:"displayCategories"`
+	Cookie            common.Cookie                  `json:"cookie"`
+	Capabilities      []Capability                   `json:"capabilities"`
+
+	AdditionalAttributes *discoverable.AdditionalAttributes   `json:"additionalAttributes"`
+	Connections          []discoverable.Connection            `json:"connections"`
+	Relationships        map[string]discoverable.Relationship `json:"relationships"`
+}
+
+// Capability is the configuration of a discoverable.Capability. Type and version default
+// to "AlexaInterface" and "3", retrievable defaults to true.
+type Capability struct {
+	Type                string   `json:"type"`
+	Interface           string   `json:"interface"`
+	Instance            string   `json:"instance"`
+	Version             string   `json:"version"`
+	Supported           []string `json:"supported"`
+	ProactivelyReported bool     `json:"proactivelyReported"`
+	Retrievable         *bool    `json:"retrievable"`
+
+	CapabilityResources *discoverable.CapabilityResources `json:"capabilityResources"`
+}
+
+// Load reads the endpoints from the given file, the format is chosen by the file extension (.json, .yaml or .yml)
+func Load(file string) ([]discoverable.Endpoint, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []discoverable.Endpoint
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		endpoints, err = ParseJSON(data)
+	case ".yaml", ".yml":
+		endpoints, err = ParseYAML(data)
+	default:
+		return nil, fmt.Errorf("unsupported format of %s, use .json, .yaml or .yml", file)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+
+	return endpoints, nil
+}
+
+// ParseYAML parses and validates the endpoints of a YAML configuration
+func ParseYAML(data []byte) ([]discoverable.Endpoint, error) {
+	var raw interface{}
+	err := yaml.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err = convertYAML(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJSON(data)
+}
+
+// ParseJSON parses and validates the endpoints of a JSON configuration
+func ParseJSON(data []byte) ([]discoverable.Endpoint, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var c Config
+	err := decoder.Decode(&c)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Build()
+}
+
+// Build creates and validates the configured endpoints
+func (c Config) Build() ([]discoverable.Endpoint, error) {
+	endpoints := make([]discoverable.Endpoint, 0, len(c.Endpoints))
+	known := make(map[string]int)
+
+	for i, ec := range c.Endpoints {
+		ep := ec.Build()
+
+		if err := ep.Validate(); err != nil {
+			return nil, fmt.Errorf("endpoints[%d] (%s): %v", i, ep.EndpointID, err)
+		}
+
+		if j, ok := known[ep.EndpointID]; ok {
+			return nil, fmt.Errorf("endpoints[%d] (%s): endpointId already used by endpoints[%d]", i, ep.EndpointID, j)
+		}
+		known[ep.EndpointID] = i
+
+		endpoints = append(endpoints, ep)
+	}
+
+	for i, ep := range endpoints {
+		for name, r := range ep.Relationships {
+			if _, ok := known[r.EndpointID]; !ok {
+				return nil, fmt.Errorf("endpoints[%d] (%s): relationships.%s: endpoint %s is unknown", i, ep.EndpointID, name, r.EndpointID)
+			}
+		}
+	}
+
+	return endpoints, nil
+}
+
+// Build creates the configured endpoint
+func (e Endpoint) Build() discoverable.Endpoint {
+	ep := discoverable.Endpoint{
+		EndpointID:        e.EndpointID,
+		FriendlyName:      e.FriendlyName,
+		Description:       e.Description,
+		ManufacturerName:  e.ManufacturerName,
+		DisplayCategories: e.DisplayCategories,
+		Cookie:            e.Cookie,
+
+		AdditionalAttributes: e.AdditionalAttributes,
+		Connections:          e.Connections,
+		Relationships:        e.Relationships,
+	}
+
+	for _, cc := range e.Capabilities {
+		ep.Capabilities = append(ep.Capabilities, cc.Build())
+	}
+
+	return ep
+}
+
+// Build creates the configured capability
+func (c Capability) Build() discoverable.Capability {
+	capability := discoverable.NewInstanceCapability(c.Interface, c.Instance, c.Supported, c.CapabilityResources)
+	capability.Properties.ProactivelyReported = c.ProactivelyReported
+
+	if c.Type != "" {
+		capability.Type = c.Type
+	}
+
+	if c.Version != "" {
+		capability.Version = c.Version
+	}
+
+	if c.Retrievable != nil {
+		capability.Properties.Retrievable = *c.Retrievable
+	}
+
+	return capability
+}
+
+// convertYAML converts maps decoded by yaml to be compatible with encoding/json
+func convertYAML(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("key %v is not a string", key)
+			}
+
+			converted, err := convertYAML(item)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = converted
+		}
+		return m, nil
+	case []interface{}:
+		for i, item := range v {
+			converted, err := convertYAML(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
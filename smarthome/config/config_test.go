@@ -0,0 +1,157 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+	"github.com/betom84/go-alexa/smarthome/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	expected := []discoverable.Endpoint{
+		{
+			EndpointID:        "light-01",
+			FriendlyName:      "Kitchen",
+			Description:       "Light in the kitchen",
+			ManufacturerName:  "TDD Inc.",
+			DisplayCategories: []discoverable.DisplayCategory{discoverable.Light},
+			Cookie:            common.Cookie{Type: "homematic", ID: "4711", Name: "Kitchen"},
+			Capabilities: []discoverable.Capability{
+				discoverable.NewCapability("Alexa.PowerController", []string{"powerState"}),
+				{
+					Type:      "AlexaInterface",
+					Interface: "Alexa.EndpointHealth",
+					Version:   "3",
+					Properties: discoverable.Properties{
+						Supported:           []discoverable.Supported{{Name: "connectivity"}},
+						ProactivelyReported: true,
+						Retrievable:         true,
+					},
+				},
+			},
+		},
+		{
+			EndpointID:        "sensor-01",
+			FriendlyName:      "Living room",
+			Description:       "Temperature in the living room",
+			ManufacturerName:  "TDD Inc.",
+			DisplayCategories: []discoverable.DisplayCategory{discoverable.TemperatureSensor},
+			Cookie:            common.Cookie{Type: "homematic", ID: "4712"},
+			AdditionalAttributes: &discoverable.AdditionalAttributes{
+				Manufacturer:    "eQ-3",
+				Model:           "HmIP-STH",
+				FirmwareVersion: "1.4.2",
+			},
+			Connections:   []discoverable.Connection{discoverable.NewZigbeeConnection("00:11:22:33:44:55:66:77")},
+			Relationships: discoverable.ConnectedBy("light-01"),
+			Capabilities: []discoverable.Capability{
+				{
+					Type:      "AlexaInterface",
+					Interface: "Alexa.TemperatureSensor",
+					Version:   "3",
+					Properties: discoverable.Properties{
+						Supported:   []discoverable.Supported{{Name: "temperature"}},
+						Retrievable: false,
+					},
+				},
+			},
+		},
+	}
+
+	tt := []struct {
+		name        string
+		file        string
+		expectError string
+	}{
+		{
+			name: "it loads endpoints from yaml",
+			file: "testdata/endpoints.yaml",
+		},
+		{
+			name: "it loads endpoints from json",
+			file: "testdata/endpoints.json",
+		},
+		{
+			name:        "it rejects unsupported formats",
+			file:        "testdata/endpoints.txt",
+			expectError: "unsupported format of testdata/endpoints.txt, use .json, .yaml or .yml",
+		},
+		{
+			name:        "it returns an error on missing file",
+			file:        "testdata/missing.yaml",
+			expectError: "open testdata/missing.yaml: no such file or directory",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoints, err := config.Load(tc.file)
+			if len(tc.expectError) > 0 {
+				assert.EqualError(t, err, tc.expectError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected, endpoints)
+		})
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	tt := []struct {
+		name        string
+		yaml        string
+		expectError string
+	}{
+		{
+			name:        "it points at the invalid endpoint",
+			yaml:        "endpoints:\n- {endpointId: a, friendlyName: A, description: A, manufacturerName: A, displayCategories: [LIGHT], capabilities: [{interface: Alexa}]}\n- {endpointId: b, friendlyName: B, description: B, manufacturerName: A, displayCategories: [LIGHT]}",
+			expectError: "endpoints[1] (b): capabilities must not be empty",
+		},
+		{
+			name:        "it points at the invalid capability",
+			yaml:        "endpoints:\n- {endpointId: a, friendlyName: A, description: A, manufacturerName: A, displayCategories: [LIGHT], capabilities: [{interface: Alexa}, {supported: [powerState]}]}",
+			expectError: "endpoints[0] (a): capabilities[1]: interface must not be empty",
+		},
+		{
+			name:        "it rejects duplicate endpoint ids",
+			yaml:        "endpoints:\n- {endpointId: a, friendlyName: A, description: A, manufacturerName: A, displayCategories: [LIGHT], capabilities: [{interface: Alexa}]}\n- {endpointId: a, friendlyName: B, description: B, manufacturerName: A, displayCategories: [LIGHT], capabilities: [{interface: Alexa}]}",
+			expectError: "endpoints[1] (a): endpointId already used by endpoints[0]",
+		},
+		{
+			name:        "it rejects relationships to unknown endpoints",
+			yaml:        "endpoints:\n- {endpointId: a, friendlyName: A, description: A, manufacturerName: A, displayCategories: [LIGHT], capabilities: [{interface: Alexa}], relationships: {isConnectedBy: {endpointId: bridge}}}",
+			expectError: "endpoints[0] (a): relationships.isConnectedBy: endpoint bridge is unknown",
+		},
+		{
+			name:        "it points at the invalid connection",
+			yaml:        "endpoints:\n- {endpointId: a, friendlyName: A, description: A, manufacturerName: A, displayCategories: [LIGHT], capabilities: [{interface: Alexa}], connections: [{type: TCP_IP}]}",
+			expectError: "endpoints[0] (a): connections[0]: macAddress of TCP_IP connection is invalid",
+		},
+		{
+			name:        "it points at invalid capability resources",
+			yaml:        "endpoints:\n- {endpointId: a, friendlyName: A, description: A, manufacturerName: A, displayCategories: [INTERIOR_BLIND], capabilities: [{interface: Alexa.RangeController, instance: Blind.Lift, supported: [rangeValue], capabilityResources: {friendlyNames: [{'@type': text, value: {text: Position}}]}}]}",
+			expectError: "endpoints[0] (a): capabilities[0]: capabilityResources of Alexa.RangeController: friendlyNames[0]: locale must not be empty",
+		},
+		{
+			name:        "it rejects unknown fields",
+			yaml:        "endpoints:\n- {endpointId: a, room: kitchen}",
+			expectError: "json: unknown field \"room\"",
+		},
+		{
+			name:        "it rejects invalid yaml",
+			yaml:        "endpoints: [",
+			expectError: "yaml: line 1: did not find expected node content",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := config.ParseYAML([]byte(tc.yaml))
+			assert.EqualError(t, err, tc.expectError)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+)
+
+// File is a discovery.EndpointSource providing the endpoints of a configuration file. Watch the file
+// to swap the provided endpoints on change.
+type File struct {
+	path string
+
+	mutex     sync.RWMutex
+	endpoints []discoverable.Endpoint
+	modTime   time.Time
+}
+
+// NewFile creates an endpoint source for the given configuration file, which gets loaded initially
+func NewFile(path string) (*File, error) {
+	f := &File{path: path}
+
+	err := f.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Endpoints returns the endpoints last loaded from file regardless of the given scope
+func (f *File) Endpoints(scope common.Scope) ([]discoverable.Endpoint, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.endpoints, nil
+}
+
+// Reload loads the configuration file and swaps the provided endpoints. On error the endpoints
+// loaded before are kept.
+func (f *File) Reload() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := Load(f.path)
+	if err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.endpoints = endpoints
+	f.modTime = info.ModTime()
+
+	return nil
+}
+
+// Watch checks the configuration file for changes in the given interval and reloads it until stop gets
+// closed. Errors occurred while reloading are passed to onError, which is optional.
+func (f *File) Watch(interval time.Duration, stop <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	f.mutex.RLock()
+	attempted := f.modTime
+	f.mutex.RUnlock()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(f.path)
+			if err == nil && info.ModTime().Equal(attempted) {
+				continue
+			}
+
+			if err == nil {
+				attempted = info.ModTime()
+				err = f.Reload()
+			}
+
+			if err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
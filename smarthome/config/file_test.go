@@ -0,0 +1,81 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const singleEndpoint = `endpoints:
+- {endpointId: a, friendlyName: A, description: A, manufacturerName: A, displayCategories: [LIGHT], capabilities: [{interface: Alexa}]}
+`
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-alexa-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "endpoints.yaml")
+	writeFile(t, path, singleEndpoint, time.Now().Add(-time.Minute))
+
+	file, err := config.NewFile(path)
+	if err != nil {
+		t.Fatalf("could not load file; %v", err)
+	}
+
+	endpoints, err := file.Endpoints(common.Scope{})
+	assert.NoError(t, err)
+	assert.Len(t, endpoints, 1)
+
+	errors := make(chan error, 10)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go file.Watch(5*time.Millisecond, stop, func(err error) { errors <- err })
+
+	writeFile(t, path, "endpoints: [{endpointId: b}]", time.Now().Add(-time.Second))
+	select {
+	case err := <-errors:
+		assert.Contains(t, err.Error(), "endpoints[0] (b): friendlyName must not be empty")
+	case <-time.After(time.Second):
+		t.Fatal("invalid configuration was not reported")
+	}
+
+	endpoints, _ = file.Endpoints(common.Scope{})
+	assert.Equal(t, "a", endpoints[0].EndpointID, "endpoints should be kept on invalid configuration")
+
+	writeFile(t, path, singleEndpoint+"- {endpointId: b, friendlyName: B, description: B, manufacturerName: B, displayCategories: [LIGHT], capabilities: [{interface: Alexa}]}\n", time.Now())
+	deadline := time.Now().Add(time.Second)
+	for endpoints, _ = file.Endpoints(common.Scope{}); len(endpoints) != 2; endpoints, _ = file.Endpoints(common.Scope{}) {
+		if time.Now().After(deadline) {
+			t.Fatal("changed configuration was not reloaded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	_, err = config.NewFile(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func writeFile(t *testing.T, path string, content string, modTime time.Time) {
+	t.Helper()
+
+	err := ioutil.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Chtimes(path, modTime, modTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,109 @@
+package smarthome
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+)
+
+// ConformanceReport lists all mismatches between the discoverable endpoints and the devices created for them
+type ConformanceReport struct {
+	Mismatches []ConformanceMismatch
+}
+
+// ConformanceMismatch describes why the device of an endpoint is not able to process the directives expected by alexa
+type ConformanceMismatch struct {
+	EndpointID string
+	Cookie     common.Cookie
+
+	// Err is set, if the DeviceFactory failed to create the device
+	Err error
+
+	// Unsatisfied capabilities the device doesn't implement
+	Unsatisfied []discoverable.Capability
+}
+
+// OK is true if no mismatches were found
+func (r ConformanceReport) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+func (r ConformanceReport) String() string {
+	if r.OK() {
+		return "all endpoints conform to their devices"
+	}
+
+	var buffer bytes.Buffer
+	for _, m := range r.Mismatches {
+		buffer.WriteString(m.String())
+		buffer.WriteString("\n")
+	}
+
+	return buffer.String()
+}
+
+func (m ConformanceMismatch) String() string {
+	if m.Err != nil {
+		return fmt.Sprintf("%s (%s/%s): unable to create device; %v", m.EndpointID, m.Cookie.Type, m.Cookie.ID, m.Err)
+	}
+
+	var interfaces []string
+	for _, c := range m.Unsatisfied {
+		interfaces = append(interfaces, c.Interface)
+	}
+
+	return fmt.Sprintf("%s (%s/%s): device doesn't implement %v", m.EndpointID, m.Cookie.Type, m.Cookie.ID, interfaces)
+}
+
+// CheckConformance creates the device of every endpoint provided by the EndpointSource and verifies
+// that it implements all interfaces required by the endpoint capabilities. Use it before starting the
+// server to detect misconfigured endpoints. The endpoint source is queried with an empty scope, or for
+// each user if it provides the names of all users (like Authority in multi-tenant mode).
+func (h *Handler) CheckConformance() (ConformanceReport, error) {
+	report := ConformanceReport{}
+
+	if h.EndpointSource == nil || h.DeviceFactory == nil {
+		return report, fmt.Errorf("endpoint source or device factory not specified")
+	}
+
+	scopes := []common.Scope{{}}
+	if users, ok := h.EndpointSource.(interface{ UserNames() []string }); ok {
+		scopes = scopes[:0]
+		for _, name := range users.UserNames() {
+			scopes = append(scopes, common.Scope{Identity: &common.Identity{Email: name}})
+		}
+	}
+
+	for _, scope := range scopes {
+		endpoints, err := h.EndpointSource.Endpoints(scope)
+		if err != nil {
+			return report, fmt.Errorf("could not query endpoints; %v", err)
+		}
+
+		report.Mismatches = append(report.Mismatches, h.checkEndpointConformance(scope.Identity, endpoints)...)
+	}
+
+	return report, nil
+}
+
+func (h *Handler) checkEndpointConformance(user *common.Identity, endpoints []discoverable.Endpoint) (mismatches []ConformanceMismatch) {
+	for _, ep := range endpoints {
+		mismatch := ConformanceMismatch{EndpointID: ep.EndpointID, Cookie: ep.Cookie}
+
+		dir := &common.Directive{Endpoint: &common.Endpoint{EndpointID: ep.EndpointID, Cookie: ep.Cookie}, Identity: user}
+		device, err := h.newDevice(dir)
+		if err != nil {
+			mismatch.Err = err
+		} else {
+			mismatch.Unsatisfied = discoverable.Unsatisfied(device, ep.Capabilities)
+		}
+
+		if mismatch.Err != nil || len(mismatch.Unsatisfied) > 0 {
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+
+	return
+}
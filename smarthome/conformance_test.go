@@ -0,0 +1,76 @@
+package smarthome_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+	"github.com/betom84/go-alexa/smarthome/directives/discovery"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConformance(t *testing.T) {
+	power := discoverable.NewCapability("Alexa.PowerController", []string{"powerState"})
+	temperature := discoverable.NewCapability("Alexa.TemperatureSensor", []string{"temperature"})
+
+	endpoints := discovery.StaticEndpoints{
+		{EndpointID: "light-01", Cookie: common.Cookie{Type: "light", ID: "01"}, Capabilities: []discoverable.Capability{power}},
+		{EndpointID: "light-02", Cookie: common.Cookie{Type: "light", ID: "02"}, Capabilities: []discoverable.Capability{power, temperature}},
+		{EndpointID: "gone-01", Cookie: common.Cookie{Type: "gone", ID: "01"}, Capabilities: []discoverable.Capability{power}},
+	}
+
+	factory := &mocks.MockDeviceFactory{}
+	factory.On("NewDevice", "light", "01").Return(&mocks.MockPowerDevice{}, nil)
+	factory.On("NewDevice", "light", "02").Return(&mocks.MockPowerDevice{}, nil)
+	factory.On("NewDevice", "gone", "01").Return(nil, fmt.Errorf("unknown device"))
+	defer factory.AssertExpectations(t)
+
+	handler := smarthome.NewDefaultHandlerWithSource(nil, endpoints)
+	handler.DeviceFactory = factory
+
+	report, err := handler.CheckConformance()
+	assert.NoError(t, err)
+	assert.False(t, report.OK())
+
+	assert.Equal(t, []smarthome.ConformanceMismatch{
+		{EndpointID: "light-02", Cookie: common.Cookie{Type: "light", ID: "02"}, Unsatisfied: []discoverable.Capability{temperature}},
+		{EndpointID: "gone-01", Cookie: common.Cookie{Type: "gone", ID: "01"}, Err: fmt.Errorf("unknown device")},
+	}, report.Mismatches)
+
+	assert.Equal(t, "light-02 (light/02): device doesn't implement [Alexa.TemperatureSensor]\n"+
+		"gone-01 (gone/01): unable to create device; unknown device\n", report.String())
+}
+
+func TestCheckConformanceWithoutMismatches(t *testing.T) {
+	factory := &mocks.MockDeviceFactory{}
+	factory.On("NewDevice", "light", "01").Return(&mocks.MockPowerDevice{}, nil)
+
+	handler := smarthome.NewDefaultHandler(nil, []discoverable.Endpoint{
+		{EndpointID: "light-01", Cookie: common.Cookie{Type: "light", ID: "01"}, Capabilities: discoverable.CapabilitiesOf(&mocks.MockPowerDevice{})},
+	})
+	handler.DeviceFactory = factory
+
+	report, err := handler.CheckConformance()
+	assert.NoError(t, err)
+	assert.True(t, report.OK())
+	assert.Equal(t, "all endpoints conform to their devices", report.String())
+}
+
+func TestCheckConformanceErrors(t *testing.T) {
+	_, err := (&smarthome.Handler{}).CheckConformance()
+	assert.EqualError(t, err, "endpoint source or device factory not specified")
+
+	handler := smarthome.Handler{
+		DeviceFactory: &mocks.MockDeviceFactory{},
+		EndpointSource: discovery.EndpointSourceFunc(func(common.Scope) ([]discoverable.Endpoint, error) {
+			return nil, fmt.Errorf("database is gone")
+		}),
+	}
+
+	_, err = handler.CheckConformance()
+	assert.EqualError(t, err, "could not query endpoints; database is gone")
+}
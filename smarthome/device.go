@@ -0,0 +1,98 @@
+package smarthome
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+)
+
+// DeviceErrorReason describes why a DeviceFactory could not create a device
+type DeviceErrorReason int
+
+const (
+	// DeviceNotFound is answered with NO_SUCH_ENDPOINT
+	DeviceNotFound DeviceErrorReason = iota
+
+	// DeviceUnreachable is answered with ENDPOINT_UNREACHABLE
+	DeviceUnreachable
+
+	// DeviceLowPower is answered with ENDPOINT_LOW_POWER
+	DeviceLowPower
+
+	// BridgeUnreachable is answered with BRIDGE_UNREACHABLE
+	BridgeUnreachable
+)
+
+// DeviceError is returned by a DeviceFactory to tell the handler why a device could not be created. The
+// directive is answered with the according alexa error, without being processed. It may be wrapped.
+type DeviceError struct {
+	Reason  DeviceErrorReason
+	Message string
+}
+
+func (e DeviceError) Error() string {
+	return e.Message
+}
+
+// AlexaError returns the error to respond with
+func (e DeviceError) AlexaError() common.AlexaError {
+	switch e.Reason {
+	case DeviceNotFound:
+		return common.NewNoSuchEndpointError(e.Message)
+	case DeviceUnreachable:
+		return common.NewEndpointUnreachableError(e.Message)
+	case DeviceLowPower:
+		return common.NewEndpointLowPowerError(e.Message)
+	case BridgeUnreachable:
+		return common.NewBridgeUnreachableError(e.Message)
+	default:
+		return common.NewInternalError(e.Message)
+	}
+}
+
+// NewDeviceNotFoundError creates a DeviceError to indicate that the device does not exist
+func NewDeviceNotFoundError(message string) DeviceError {
+	return DeviceError{DeviceNotFound, message}
+}
+
+// NewDeviceUnreachableError creates a DeviceError to indicate that the device is offline
+func NewDeviceUnreachableError(message string) DeviceError {
+	return DeviceError{DeviceUnreachable, message}
+}
+
+// NewDeviceLowPowerError creates a DeviceError to indicate that the device is in a low power state
+func NewDeviceLowPowerError(message string) DeviceError {
+	return DeviceError{DeviceLowPower, message}
+}
+
+// NewBridgeUnreachableError creates a DeviceError to indicate that the bridge of the device is offline
+func NewBridgeUnreachableError(message string) DeviceError {
+	return DeviceError{BridgeUnreachable, message}
+}
+
+func (h *Handler) newDevice(dir *common.Directive) (interface{}, error) {
+	if h.DeviceFactory == nil {
+		return nil, fmt.Errorf("device factory not specified")
+	}
+
+	if f, ok := h.DeviceFactory.(UserDeviceFactory); ok && dir.Identity != nil {
+		return f.NewUserDevice(dir.Identity, dir.Endpoint.Cookie.Type, dir.Endpoint.Cookie.ID)
+	}
+
+	return h.DeviceFactory.NewDevice(dir.Endpoint.Cookie.Type, dir.Endpoint.Cookie.ID)
+}
+
+func deviceAlexaError(err error) common.AlexaError {
+	var deviceErr DeviceError
+	if errors.As(err, &deviceErr) {
+		return deviceErr.AlexaError()
+	}
+
+	var alexaErr common.AlexaError
+	if errors.As(err, &alexaErr) {
+		return alexaErr
+	}
+
+	return common.NewInternalError(fmt.Sprintf("could not create endpoint device; %v", err))
+}
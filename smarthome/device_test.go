@@ -0,0 +1,103 @@
+package smarthome_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandlerDeviceErrors(t *testing.T) {
+	common.ConstMessageID = "any-const-message-id-for-test"
+
+	tt := []struct {
+		name            string
+		err             error
+		expectedType    string
+		expectedMessage string
+	}{
+		{
+			name:            "it responds with no such endpoint",
+			err:             smarthome.NewDeviceNotFoundError("device was removed"),
+			expectedType:    "NO_SUCH_ENDPOINT",
+			expectedMessage: "device was removed",
+		},
+		{
+			name:            "it responds with endpoint unreachable",
+			err:             smarthome.NewDeviceUnreachableError("device is offline"),
+			expectedType:    "ENDPOINT_UNREACHABLE",
+			expectedMessage: "device is offline",
+		},
+		{
+			name:            "it responds with endpoint low power",
+			err:             smarthome.NewDeviceLowPowerError("battery is empty"),
+			expectedType:    "ENDPOINT_LOW_POWER",
+			expectedMessage: "battery is empty",
+		},
+		{
+			name:            "it responds with bridge unreachable",
+			err:             smarthome.NewBridgeUnreachableError("bridge is offline"),
+			expectedType:    "BRIDGE_UNREACHABLE",
+			expectedMessage: "bridge is offline",
+		},
+		{
+			name:            "it unwraps device errors",
+			err:             fmt.Errorf("could not connect; %w", smarthome.NewDeviceUnreachableError("device is offline")),
+			expectedType:    "ENDPOINT_UNREACHABLE",
+			expectedMessage: "device is offline",
+		},
+		{
+			name:            "it responds with alexa errors of factory",
+			err:             common.NewInvalidDirectiveError("cookie is invalid"),
+			expectedType:    "INVALID_DIRECTIVE",
+			expectedMessage: "cookie is invalid",
+		},
+		{
+			name:            "it responds with internal error on other errors",
+			err:             fmt.Errorf("something horrible"),
+			expectedType:    "INTERNAL_ERROR",
+			expectedMessage: "could not create endpoint device; something horrible",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &mocks.MockDeviceFactory{}
+			f.On("NewDevice", "testing", "ABC-123").Return(nil, tc.err)
+
+			p := createMockDirectiveProcessor(true, nil)
+
+			handler := smarthome.Handler{DeviceFactory: f}
+			handler.AddDirectiveProcessor(p)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(readFile(t, "testdata/process_directive_request.json"))))
+
+			body, _ := ioutil.ReadAll(rec.Result().Body)
+			expected := bytes.Replace(readFile(t, "testdata/internal_error_response.json"), []byte(`"INTERNAL_ERROR"`), []byte(fmt.Sprintf("%q", tc.expectedType)), 1)
+			expected = bytes.Replace(expected, []byte(`"something horrible"`), []byte(fmt.Sprintf("%q", tc.expectedMessage)), 1)
+			assert.JSONEq(t, string(expected), string(body))
+
+			p.AssertNotCalled(t, "Process", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestHandlerWithoutDeviceFactory(t *testing.T) {
+	handler := smarthome.Handler{}
+	handler.AddDirectiveProcessor(createMockDirectiveProcessor(true, nil))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(readFile(t, "testdata/process_directive_request.json"))))
+
+	body, _ := ioutil.ReadAll(rec.Result().Body)
+	assert.Contains(t, string(body), "could not create endpoint device; device factory not specified")
+}
@@ -26,6 +26,10 @@ func (r Report) Process(dir *common.Directive, ed interface{}) (*common.Response
 		return nil, fmt.Errorf("incompatible directive")
 	}
 
+	if err := dir.DecodePayload(&common.EmptyPayload{}); err != nil {
+		return nil, err
+	}
+
 	var resp = r.createResponse(dir)
 	resp.Context = common.NewContext()
 
@@ -32,6 +32,12 @@ func TestReport(t *testing.T) {
 			directive:   helpers.CreateDirective(t, `{"header":{"namespace":"NotAlexa"}}`),
 			expectError: "incompatible directive",
 		},
+		{
+			name:        "it returns an error on unexpected payload",
+			directive:   helpers.CreateDirective(t, `{"header":{"namespace":"Alexa","name":"ReportState"},"payload":{"state":"ON"}}`),
+			device:      &mocks.MockPowerDevice{},
+			expectError: "invalid payload field state; is not expected",
+		},
 		{
 			name:       "it can handle unspecific devices without reporting anything",
 			directive:  helpers.LoadRequest(t, "testdata/request.json"),
@@ -2,19 +2,19 @@
 package authorization
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
 
 	"github.com/betom84/go-alexa/smarthome/common"
 )
 
-// RequestTokenURL to change for tests
+// RequestTokenURL is the default token URL of the LWA identity provider.
+//
+// Deprecated: Set LWA.TokenURL instead.
 var RequestTokenURL = "https://api.amazon.com/auth/o2/token"
 
-// RequestUserProfileURL to change for tests
+// RequestUserProfileURL is the default profile URL of the LWA identity provider.
+//
+// Deprecated: Set LWA.ProfileURL instead.
 var RequestUserProfileURL = "https://api.amazon.com/user/profile"
 
 // Authority represents the instance where an alexa user gets access granted
@@ -28,6 +28,9 @@ type Authority interface {
 // for async directive responses
 type Authorization struct {
 	Authority Authority
+
+	// Provider of the alexa user identity, defaults to LWA
+	Provider IdentityProvider
 }
 
 // IsCapable checks if an common.Directive is an authorization directive
@@ -45,74 +48,37 @@ func (a Authorization) Process(dir *common.Directive, device interface{}) (*comm
 		return nil, fmt.Errorf("authority is missing")
 	}
 
-	grantee := dir.Payload["grantee"].(map[string]interface{})
-	profile, err := a.retrieveGranteeProfile(grantee["token"].(string))
-	if err != nil {
-		return nil, err
-	}
-
-	grant := dir.Payload["grant"].(map[string]interface{})
-	tokens, err := a.retrieveAccessTokens(grant["code"].(string))
-	if err != nil {
-		return nil, err
-	}
-
-	err = a.Authority.AcceptGrant(profile["email"].(string), grantee["token"].(string), tokens)
-	if err != nil {
-		return nil, common.NewAcceptGrantFailedError(err.Error())
-	}
-
-	return a.createResponse(), nil
-}
-
-func (a Authorization) retrieveGranteeProfile(granteeToken string) (map[string]interface{}, error) {
-	request, err := http.NewRequest("GET", RequestUserProfileURL, nil)
+	var payload common.AcceptGrantPayload
+	err := dir.DecodePayload(&payload)
 	if err != nil {
 		return nil, err
 	}
 
-	request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", granteeToken))
-
-	client := new(http.Client)
-	response, err := client.Do(request)
-	if err != nil {
-		return nil, err
+	provider := a.Provider
+	if provider == nil {
+		provider = LWA{}
 	}
 
-	body, err := ioutil.ReadAll(response.Body)
+	profile, err := provider.Profile(payload.Grantee.Token)
 	if err != nil {
 		return nil, err
 	}
 
-	var granteeProfile map[string]interface{}
-	err = json.Unmarshal(body, &granteeProfile)
-
-	return granteeProfile, err
-}
-
-func (a Authorization) retrieveAccessTokens(code string) (map[string]interface{}, error) {
-	resp, err := http.PostForm(RequestTokenURL, url.Values{
-		"grant_type":    {"authorization_code"},
-		"code":          {code},
-		"client_id":     {a.Authority.GetClientID()},
-		"client_secret": {a.Authority.GetClientSecret()}})
-
-	if err != nil {
-		return nil, err
+	if profile.Email == "" {
+		return nil, common.NewAcceptGrantFailedError("profile of grantee does not contain an e-mail address")
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	tokens, err := provider.ExchangeCode(payload.Grant.Code, a.Authority.GetClientID(), a.Authority.GetClientSecret())
 	if err != nil {
 		return nil, err
 	}
 
-	var tokens map[string]interface{}
-	err = json.Unmarshal(body, &tokens)
+	err = a.Authority.AcceptGrant(profile.Email, payload.Grantee.Token, tokens)
 	if err != nil {
-		return nil, err
+		return nil, common.NewAcceptGrantFailedError(err.Error())
 	}
 
-	return tokens, nil
+	return a.createResponse(), nil
 }
 
 func (a Authorization) createResponse() *common.Response {
@@ -41,6 +41,18 @@ func TestAuthorization(t *testing.T) {
 			directive:   helpers.CreateDirective(t, `{"header":{"namespace":"Alexa.Authorization"}}`),
 			expectError: "authority is missing",
 		},
+		{
+			name:          "it returns an error on malformed payload",
+			directive:     helpers.CreateDirective(t, `{"header":{"namespace":"Alexa.Authorization"},"payload":{"grant":"code","grantee":{}}}`),
+			expectError:   "invalid payload field grant; must be of type common.Grant",
+			mockAuthority: &mocks.MockAuthority{},
+		},
+		{
+			name:          "it returns an error on missing grantee token",
+			directive:     helpers.CreateDirective(t, `{"header":{"namespace":"Alexa.Authorization"},"payload":{"grant":{"type":"OAuth2.AuthorizationCode","code":"code"},"grantee":{"type":"BearerToken"}}}`),
+			expectError:   "invalid payload field grantee.token; is missing",
+			mockAuthority: &mocks.MockAuthority{},
+		},
 		{
 			name:            "it responds successful when authority returns no error",
 			directive:       helpers.LoadRequest(t, "testdata/request.json"),
@@ -0,0 +1,67 @@
+package authorization
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+)
+
+// OIDC is a generic OpenID Connect identity provider for the profile of the grantee, it's requested from the
+// userinfo endpoint and must contain the email claim. The grant code of AcceptGrant directives is issued by
+// login with amazon to send events to the event gateway, therefore it's always exchanged by the embedded LWA.
+type OIDC struct {
+	// LWA exchanges the grant code, its Client is used to request the userinfo as well
+	LWA
+
+	// UserInfoURL of the userinfo endpoint
+	UserInfoURL string
+}
+
+// NewOIDC creates an identity provider with the userinfo endpoint taken from the discovery document of the issuer
+func NewOIDC(issuer string) (*OIDC, error) {
+	discoveryURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+
+	resp, err := http.Get(discoveryURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not request discovery document; %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not request discovery document; %s", resp.Status)
+	}
+
+	var config struct {
+		UserInfoEndpoint string `json:"userinfo_endpoint"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&config)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode discovery document; %v", err)
+	}
+
+	if config.UserInfoEndpoint == "" {
+		return nil, fmt.Errorf("discovery document does not contain userinfo endpoint")
+	}
+
+	return &OIDC{UserInfoURL: config.UserInfoEndpoint}, nil
+}
+
+// Profile requests the claims of the grantee from the userinfo endpoint
+func (p OIDC) Profile(granteeToken string) (*common.Identity, error) {
+	var claims struct {
+		Subject string `json:"sub"`
+		Email   string `json:"email"`
+		Name    string `json:"name"`
+	}
+
+	err := requestProfile(p.Client, p.UserInfoURL, granteeToken, &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.Identity{Email: claims.Email, UserID: claims.Subject, Name: claims.Name}, nil
+}
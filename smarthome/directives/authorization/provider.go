@@ -0,0 +1,133 @@
+package authorization
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+)
+
+// IdentityProvider is the oauth2 provider used for account linking, it exchanges the grant code
+// and looks up the profile of the grantee
+type IdentityProvider interface {
+	// ExchangeCode requests the access and refresh tokens for the grant code
+	ExchangeCode(code string, clientID string, clientSecret string) (map[string]interface{}, error)
+
+	// Profile requests the identity of the grantee token
+	Profile(granteeToken string) (*common.Identity, error)
+}
+
+// ProviderAuthority is an Authority which specifies the identity provider used for account linking
+type ProviderAuthority interface {
+	Authority
+	GetIdentityProvider() IdentityProvider
+}
+
+// LWA is the login with amazon identity provider
+type LWA struct {
+	// TokenURL defaults to RequestTokenURL
+	TokenURL string
+
+	// ProfileURL defaults to RequestUserProfileURL
+	ProfileURL string
+
+	// Client to send http requests, defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// ExchangeCode requests the access and refresh tokens from login with amazon
+func (p LWA) ExchangeCode(code string, clientID string, clientSecret string) (map[string]interface{}, error) {
+	tokenURL := p.TokenURL
+	if tokenURL == "" {
+		tokenURL = RequestTokenURL
+	}
+
+	return exchangeCode(p.Client, tokenURL, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret}})
+}
+
+// Profile requests the amazon profile of the grantee
+func (p LWA) Profile(granteeToken string) (*common.Identity, error) {
+	profileURL := p.ProfileURL
+	if profileURL == "" {
+		profileURL = RequestUserProfileURL
+	}
+
+	var profile struct {
+		UserID string `json:"user_id"`
+		Email  string `json:"email"`
+		Name   string `json:"name"`
+	}
+
+	err := requestProfile(p.Client, profileURL, granteeToken, &profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.Identity{Email: profile.Email, UserID: profile.UserID, Name: profile.Name}, nil
+}
+
+func exchangeCode(client *http.Client, tokenURL string, values url.Values) (map[string]interface{}, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.PostForm(tokenURL, values)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not exchange code; %s %s", resp.Status, body)
+	}
+
+	var tokens map[string]interface{}
+	err = json.Unmarshal(body, &tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func requestProfile(client *http.Client, profileURL string, token string, profile interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	request, err := http.NewRequest("GET", profileURL, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	resp, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not request profile; %s %s", resp.Status, body)
+	}
+
+	return json.Unmarshal(body, profile)
+}
@@ -0,0 +1,102 @@
+package authorization_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/directives/authorization"
+	"github.com/betom84/go-alexa/smarthome/testdata/helpers"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLWA(t *testing.T) {
+	handler := createMockHTTPHandler(t, "testdata/profile.json", "testdata/tokens.json")
+	defer handler.AssertExpectations(t)
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	provider := authorization.LWA{TokenURL: srv.URL + "/auth/o2/token", ProfileURL: srv.URL + "/user/profile"}
+
+	profile, err := provider.Profile("grantee-token")
+	assert.NoError(t, err)
+	assert.Equal(t, &common.Identity{Email: "mhashimoto-04@plaxo.com", UserID: "amznl.account.K2LI23KL2LK2", Name: "Mork Hashimoto"}, profile)
+
+	tokens, err := provider.ExchangeCode("code", "clientID", "clientSecret")
+	assert.NoError(t, err)
+	assert.Equal(t, "Atzr|IQEBLzAtAhRPpMJxdwVz2Nn6f2y-tpJX2DeX...", tokens["refresh_token"])
+}
+
+func TestOIDC(t *testing.T) {
+	handler := &mocks.MockHTTPHandler{T: t}
+	defer handler.AssertExpectations(t)
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	handler.On("RequestURI", "GET", "/.well-known/openid-configuration", mock.Anything).Return([]byte(fmt.Sprintf(`{"token_endpoint":"%[1]s/token","userinfo_endpoint":"%[1]s/userinfo"}`, srv.URL)), http.StatusOK)
+	handler.On("RequestURI", "GET", "/userinfo", mock.Anything).Return([]byte(`{"sub":"248289761001","email":"somebody@mail.com","name":"Some Body"}`), http.StatusOK)
+	handler.On("RequestURI", "POST", "/auth/o2/token", []byte("client_id=clientID&client_secret=clientSecret&code=code&grant_type=authorization_code")).Return([]byte(`{"access_token":"access","refresh_token":"refresh","expires_in":3600}`), http.StatusOK)
+
+	provider, err := authorization.NewOIDC(srv.URL + "/")
+	assert.NoError(t, err)
+	assert.Equal(t, "", provider.TokenURL, "grant code must be exchanged with login with amazon by default")
+	provider.TokenURL = srv.URL + "/auth/o2/token"
+
+	profile, err := provider.Profile("grantee-token")
+	assert.NoError(t, err)
+	assert.Equal(t, &common.Identity{Email: "somebody@mail.com", UserID: "248289761001", Name: "Some Body"}, profile)
+
+	tokens, err := provider.ExchangeCode("code", "clientID", "clientSecret")
+	assert.NoError(t, err)
+	assert.Equal(t, "refresh", tokens["refresh_token"])
+}
+
+func TestOIDCErrors(t *testing.T) {
+	handler := &mocks.MockHTTPHandler{T: t}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	handler.On("RequestURI", "GET", "/.well-known/openid-configuration", mock.Anything).Return([]byte(`{}`), http.StatusOK)
+	handler.On("RequestURI", "GET", "/userinfo", mock.Anything).Return([]byte(`invalid token`), http.StatusUnauthorized)
+	handler.On("RequestURI", "POST", "/auth/o2/token", mock.Anything).Return([]byte(`{"error":"invalid_grant"}`), http.StatusBadRequest)
+
+	_, err := authorization.NewOIDC(srv.URL)
+	assert.EqualError(t, err, "discovery document does not contain userinfo endpoint")
+
+	provider := authorization.OIDC{LWA: authorization.LWA{TokenURL: srv.URL + "/auth/o2/token"}, UserInfoURL: srv.URL + "/userinfo"}
+
+	_, err = provider.Profile("grantee-token")
+	assert.EqualError(t, err, "could not request profile; 401 Unauthorized invalid token")
+
+	_, err = provider.ExchangeCode("code", "clientID", "clientSecret")
+	assert.EqualError(t, err, `could not exchange code; 400 Bad Request {"error":"invalid_grant"}`)
+}
+
+func TestAuthorizationWithProvider(t *testing.T) {
+	provider := &mocks.MockIdentityProvider{}
+	provider.On("Profile", "access-token-from-skill").Return(&common.Identity{Email: "somebody@mail.com"}, nil)
+	provider.On("ExchangeCode", "VGhpcyBpcyBhbiBhdXRob3JpemF0aW9uIGNvZGUuIDotKQ==", "clientID", "clientSecret").Return(map[string]interface{}{"access_token": "access"}, nil)
+	defer provider.AssertExpectations(t)
+
+	authority := createMockAuthority(t, "somebody@mail.com")
+	defer authority.AssertExpectations(t)
+
+	auth := authorization.Authorization{Authority: authority, Provider: provider}
+	resp, err := auth.Process(helpers.LoadRequest(t, "testdata/request.json"), nil)
+	assert.NoError(t, err)
+	helpers.AssertEqualsGolden(t, "testdata/response.json", resp)
+
+	provider = &mocks.MockIdentityProvider{}
+	provider.On("Profile", mock.Anything).Return(&common.Identity{UserID: "anonymous"}, nil)
+
+	auth = authorization.Authorization{Authority: authority, Provider: provider}
+	_, err = auth.Process(helpers.LoadRequest(t, "testdata/request.json"), nil)
+	assert.EqualError(t, err, "profile of grantee does not contain an e-mail address")
+}
@@ -19,14 +19,27 @@ type DirectiveProcessor interface {
 	IsCapable(*common.Directive) bool
 }
 
-// CreateAuthorizeDirectiveProcessor returns a DirectiveProcessor to process authorization directives
+// CreateAuthorizeDirectiveProcessor returns a DirectiveProcessor to process authorization directives, the identity
+// provider is taken from the authority if it implements authorization.ProviderAuthority
 func CreateAuthorizeDirectiveProcessor(authority authorization.Authority) DirectiveProcessor {
-	return authorization.Authorization{Authority: authority}
+	processor := authorization.Authorization{Authority: authority}
+	if pa, ok := authority.(authorization.ProviderAuthority); ok {
+		processor.Provider = pa.GetIdentityProvider()
+	}
+
+	return processor
 }
 
 // CreateDiscoveryDirectiveProcessor returns a DirectiveProcessor to process discovery directives
+// for a static set of endpoints
 func CreateDiscoveryDirectiveProcessor(endpoints []discoverable.Endpoint) DirectiveProcessor {
-	return discovery.Discovery{Endpoints: endpoints}
+	return CreateDynamicDiscoveryDirectiveProcessor(discovery.StaticEndpoints(endpoints))
+}
+
+// CreateDynamicDiscoveryDirectiveProcessor returns a DirectiveProcessor to process discovery directives
+// with endpoints queried from the given source
+func CreateDynamicDiscoveryDirectiveProcessor(source discovery.EndpointSource) DirectiveProcessor {
+	return discovery.Discovery{Source: source}
 }
 
 // CreatePowerControllerDirectiveProcessor returns a DirectiveProcessor to process power controller directives
@@ -5,6 +5,8 @@ import (
 
 	"github.com/betom84/go-alexa/smarthome/common/discoverable"
 	"github.com/betom84/go-alexa/smarthome/directives"
+	"github.com/betom84/go-alexa/smarthome/directives/authorization"
+	"github.com/betom84/go-alexa/smarthome/directives/discovery"
 	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
 
 	"github.com/stretchr/testify/assert"
@@ -13,6 +15,26 @@ import (
 func TestFactory(t *testing.T) {
 	assert.NotNil(t, directives.CreateAuthorizeDirectiveProcessor(&mocks.MockAuthority{}))
 	assert.NotNil(t, directives.CreateDiscoveryDirectiveProcessor([]discoverable.Endpoint{}))
+	assert.NotNil(t, directives.CreateDynamicDiscoveryDirectiveProcessor(discovery.StaticEndpoints{}))
 	assert.NotNil(t, directives.CreatePowerControllerDirectiveProcessor())
 	assert.NotNil(t, directives.CreateReportAlexaDirectiveProcessor())
 }
+
+type providerAuthority struct {
+	mocks.MockAuthority
+	provider authorization.IdentityProvider
+}
+
+func (a *providerAuthority) GetIdentityProvider() authorization.IdentityProvider {
+	return a.provider
+}
+
+func TestFactoryTakesIdentityProviderOfAuthority(t *testing.T) {
+	provider := authorization.OIDC{UserInfoURL: "https://example.com/userinfo"}
+
+	p := directives.CreateAuthorizeDirectiveProcessor(&providerAuthority{provider: provider})
+	assert.Equal(t, provider, p.(authorization.Authorization).Provider)
+
+	p = directives.CreateAuthorizeDirectiveProcessor(&mocks.MockAuthority{})
+	assert.Nil(t, p.(authorization.Authorization).Provider)
+}
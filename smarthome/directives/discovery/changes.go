@@ -0,0 +1,271 @@
+package discovery
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+)
+
+// EventGateway sends events on behalf of the linked alexa users, see gateway.Gateway
+type EventGateway interface {
+	// Users returns all linked alexa users
+	Users() ([]string, error)
+
+	// Scope returns the scope to send events for the given user
+	Scope(user string) (common.Scope, error)
+
+	// GranteeScope returns the scope the given user got granted with
+	GranteeScope(user string) (common.Scope, error)
+
+	// Send the event authorized by the given scope
+	Send(scope common.Scope, event *common.Response) error
+}
+
+// ReportedEndpoints keeps the endpoints last reported to alexa per user. Implement it to keep them across
+// restarts, e.g. within a database.
+type ReportedEndpoints interface {
+	// Load the endpoints last reported for the user, nil if none were reported yet
+	Load(user string) ([]discoverable.Endpoint, error)
+
+	// Save the endpoints reported for the user
+	Save(user string, endpoints []discoverable.Endpoint) error
+}
+
+// MemoryReportedEndpoints is a ReportedEndpoints which holds the endpoints in memory only
+type MemoryReportedEndpoints struct {
+	mutex     sync.Mutex
+	endpoints map[string][]discoverable.Endpoint
+}
+
+// Load the endpoints last reported for the user
+func (m *MemoryReportedEndpoints) Load(user string) ([]discoverable.Endpoint, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.endpoints[user], nil
+}
+
+// Save the endpoints reported for the user
+func (m *MemoryReportedEndpoints) Save(user string, endpoints []discoverable.Endpoint) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.endpoints == nil {
+		m.endpoints = make(map[string][]discoverable.Endpoint)
+	}
+
+	m.endpoints[user] = endpoints
+
+	return nil
+}
+
+// ChangeReporter reports changes of the endpoints provided by a dynamic source to alexa, so users
+// don't need to discover devices again after endpoints got added or removed.
+type ChangeReporter struct {
+	Source  EndpointSource
+	Gateway EventGateway
+
+	// Reported keeps the endpoints last reported, defaults to MemoryReportedEndpoints. With endpoints kept in
+	// memory, endpoints removed while the process was down are never reported as deleted and all endpoints
+	// are reported as added or updated again after a restart.
+	Reported ReportedEndpoints
+
+	// OnError gets called for errors occurred while running periodically, optional
+	OnError func(error)
+
+	mutex sync.Mutex
+}
+
+// Report diffs the endpoints of the source against the endpoints last reported for every linked user
+// and sends AddOrUpdateReport and DeleteReport events for the differences. On the first report for a
+// user all endpoints are reported as added or updated, because the endpoints known by alexa are unknown.
+func (r *ChangeReporter) Report() error {
+	if r.Source == nil || r.Gateway == nil {
+		return fmt.Errorf("source or gateway not specified")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.Reported == nil {
+		r.Reported = &MemoryReportedEndpoints{}
+	}
+
+	users, err := r.Gateway.Users()
+	if err != nil {
+		return fmt.Errorf("could not get linked users; %v", err)
+	}
+
+	var failed []string
+	for _, user := range users {
+		if err := r.reportUser(user); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", user, err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("could not report endpoint changes; %s", strings.Join(failed, "; "))
+	}
+
+	return nil
+}
+
+// Run reports endpoint changes in the given interval until stop gets closed
+func (r *ChangeReporter) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := r.Report(); err != nil && r.OnError != nil {
+				r.OnError(err)
+			}
+		}
+	}
+}
+
+func (r *ChangeReporter) reportUser(user string) error {
+	grantee, err := r.Gateway.GranteeScope(user)
+	if err != nil {
+		return err
+	}
+	grantee.Identity = &common.Identity{Email: user}
+
+	endpoints, err := r.Source.Endpoints(grantee)
+	if err != nil {
+		return fmt.Errorf("could not query endpoints; %v", err)
+	}
+
+	current := make(map[string]discoverable.Endpoint)
+	for _, ep := range endpoints {
+		current[ep.EndpointID] = ep
+	}
+
+	last, err := r.Reported.Load(user)
+	if err != nil {
+		return fmt.Errorf("could not load reported endpoints; %v", err)
+	}
+
+	reported := make(map[string]discoverable.Endpoint)
+	for _, ep := range last {
+		reported[ep.EndpointID] = ep
+	}
+
+	updated, deleted := diffEndpoints(reported, current)
+	if len(updated) == 0 && len(deleted) == 0 {
+		return nil
+	}
+
+	scope, err := r.Gateway.Scope(user)
+	if err != nil {
+		return err
+	}
+
+	// the reported endpoints are saved after each event sent, so a failing event doesn't repeat the others
+	if len(updated) > 0 {
+		err = r.Gateway.Send(scope, createAddOrUpdateReport(scope, updated))
+		if err != nil {
+			return err
+		}
+
+		for _, ep := range updated {
+			reported[ep.EndpointID] = ep
+		}
+
+		if err = r.save(user, reported); err != nil {
+			return err
+		}
+	}
+
+	if len(deleted) > 0 {
+		err = r.Gateway.Send(scope, createDeleteReport(scope, deleted))
+		if err != nil {
+			return err
+		}
+
+		if err = r.save(user, current); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ChangeReporter) save(user string, endpoints map[string]discoverable.Endpoint) error {
+	list := make([]discoverable.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		list = append(list, ep)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].EndpointID < list[j].EndpointID })
+
+	if err := r.Reported.Save(user, list); err != nil {
+		return fmt.Errorf("could not save reported endpoints; %v", err)
+	}
+
+	return nil
+}
+
+func diffEndpoints(previous, current map[string]discoverable.Endpoint) (updated []discoverable.Endpoint, deleted []string) {
+	for id, ep := range current {
+		if prev, ok := previous[id]; !ok || !reflect.DeepEqual(prev, ep) {
+			updated = append(updated, ep)
+		}
+	}
+
+	for id := range previous {
+		if _, ok := current[id]; !ok {
+			deleted = append(deleted, id)
+		}
+	}
+
+	sort.Slice(updated, func(i, j int) bool { return updated[i].EndpointID < updated[j].EndpointID })
+	sort.Strings(deleted)
+
+	return
+}
+
+func createAddOrUpdateReport(scope common.Scope, endpoints []discoverable.Endpoint) *common.Response {
+	event := new(common.Response)
+	event.Event.Header = common.NewHeader("AddOrUpdateReport", "Alexa.Discovery")
+	event.Event.Payload = struct {
+		Endpoints []discoverable.Endpoint `json:"endpoints"`
+		Scope     common.Scope            `json:"scope"`
+	}{
+		Endpoints: endpoints,
+		Scope:     scope,
+	}
+
+	return event
+}
+
+func createDeleteReport(scope common.Scope, endpointIDs []string) *common.Response {
+	type deleted struct {
+		EndpointID string `json:"endpointId"`
+	}
+
+	endpoints := make([]deleted, 0, len(endpointIDs))
+	for _, id := range endpointIDs {
+		endpoints = append(endpoints, deleted{id})
+	}
+
+	event := new(common.Response)
+	event.Event.Header = common.NewHeader("DeleteReport", "Alexa.Discovery")
+	event.Event.Payload = struct {
+		Endpoints []deleted    `json:"endpoints"`
+		Scope     common.Scope `json:"scope"`
+	}{
+		Endpoints: endpoints,
+		Scope:     scope,
+	}
+
+	return event
+}
@@ -0,0 +1,134 @@
+package discovery
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+	"github.com/betom84/go-alexa/smarthome/testdata/helpers"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestChangeReporter(t *testing.T) {
+	endpoints := loadEndpoints(t, "testdata/endpoints.json")
+	endpoints = append(endpoints, discoverable.Endpoint{EndpointID: "test-02", FriendlyName: "Removed"})
+
+	grantee := common.Scope{Type: "BearerToken", Token: "grantee-token"}
+	scope := common.Scope{Type: "BearerToken", Token: "event-token"}
+
+	var events []*common.Response
+
+	gw := &mocks.MockEventGateway{}
+	gw.On("Users").Return([]string{"somebody@mail.com"}, nil)
+	gw.On("GranteeScope", "somebody@mail.com").Return(grantee, nil)
+	gw.On("Scope", "somebody@mail.com").Return(scope, nil)
+	gw.On("Send", scope, mock.AnythingOfType("*common.Response")).Return(nil).Run(func(args mock.Arguments) {
+		events = append(events, args.Get(1).(*common.Response))
+	})
+	defer gw.AssertExpectations(t)
+
+	reporter := ChangeReporter{
+		Source: EndpointSourceFunc(func(s common.Scope) ([]discoverable.Endpoint, error) {
+			assert.Equal(t, grantee.Token, s.Token)
+			assert.Equal(t, &common.Identity{Email: "somebody@mail.com"}, s.Identity)
+			return endpoints, nil
+		}),
+		Gateway: gw,
+	}
+
+	assert.NoError(t, reporter.Report())
+	assert.Len(t, events, 1, "first report should add all endpoints")
+	assert.Equal(t, "AddOrUpdateReport", events[0].Event.Header.Name)
+
+	events = nil
+	assert.NoError(t, reporter.Report())
+	assert.Empty(t, events, "unchanged endpoints should not be reported")
+
+	endpoints = loadEndpoints(t, "testdata/endpoints.json")
+	endpoints[0].FriendlyName = "Renamed"
+
+	assert.NoError(t, reporter.Report())
+	if assert.Len(t, events, 2) {
+		helpers.AssertEqualsGolden(t, "testdata/add_or_update_report.json", events[0])
+		helpers.AssertEqualsGolden(t, "testdata/delete_report.json", events[1])
+	}
+}
+
+func TestChangeReporterErrors(t *testing.T) {
+	gw := &mocks.MockEventGateway{}
+	gw.On("Users").Return([]string{"anybody@mail.com", "somebody@mail.com"}, nil)
+	gw.On("GranteeScope", "anybody@mail.com").Return(common.Scope{}, fmt.Errorf("no token stored"))
+	gw.On("GranteeScope", "somebody@mail.com").Return(common.Scope{}, nil)
+	gw.On("Scope", "somebody@mail.com").Return(common.Scope{}, nil)
+	gw.On("Send", common.Scope{}, mock.Anything).Return(fmt.Errorf("gateway unavailable"))
+
+	reporter := ChangeReporter{Source: loadEndpoints(t, "testdata/endpoints.json"), Gateway: gw}
+
+	err := reporter.Report()
+	assert.EqualError(t, err, "could not report endpoint changes; anybody@mail.com: no token stored; somebody@mail.com: gateway unavailable")
+
+	assert.EqualError(t, (&ChangeReporter{}).Report(), "source or gateway not specified")
+}
+
+func TestChangeReporterPartialFailure(t *testing.T) {
+	endpoints := []discoverable.Endpoint{{EndpointID: "test-01"}, {EndpointID: "test-02"}}
+	reported := &MemoryReportedEndpoints{}
+	assert.NoError(t, reported.Save("somebody@mail.com", endpoints))
+
+	var events []string
+	named := func(name string) interface{} {
+		return mock.MatchedBy(func(event *common.Response) bool { return event.Event.Header.Name == name })
+	}
+	record := func(args mock.Arguments) { events = append(events, args.Get(1).(*common.Response).Event.Header.Name) }
+
+	gw := &mocks.MockEventGateway{}
+	gw.On("Users").Return([]string{"somebody@mail.com"}, nil)
+	gw.On("GranteeScope", "somebody@mail.com").Return(common.Scope{}, nil)
+	gw.On("Scope", "somebody@mail.com").Return(common.Scope{}, nil)
+	gw.On("Send", common.Scope{}, named("AddOrUpdateReport")).Return(nil).Run(record)
+	gw.On("Send", common.Scope{}, named("DeleteReport")).Return(fmt.Errorf("gateway unavailable")).Run(record).Once()
+	gw.On("Send", common.Scope{}, named("DeleteReport")).Return(nil).Run(record)
+
+	endpoints = []discoverable.Endpoint{{EndpointID: "test-01", FriendlyName: "Renamed"}}
+	reporter := ChangeReporter{Source: StaticEndpoints(endpoints), Gateway: gw, Reported: reported}
+
+	assert.EqualError(t, reporter.Report(), "could not report endpoint changes; somebody@mail.com: gateway unavailable")
+	assert.Equal(t, []string{"AddOrUpdateReport", "DeleteReport"}, events)
+
+	events = nil
+	assert.NoError(t, reporter.Report())
+	assert.Equal(t, []string{"DeleteReport"}, events, "successfully reported update should not be sent again")
+
+	last, err := reported.Load("somebody@mail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, endpoints, last)
+}
+
+func TestChangeReporterAfterRestart(t *testing.T) {
+	var events []*common.Response
+
+	gw := &mocks.MockEventGateway{}
+	gw.On("Users").Return([]string{"somebody@mail.com"}, nil)
+	gw.On("GranteeScope", "somebody@mail.com").Return(common.Scope{}, nil)
+	gw.On("Scope", "somebody@mail.com").Return(common.Scope{}, nil)
+	gw.On("Send", common.Scope{}, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		events = append(events, args.Get(1).(*common.Response))
+	})
+
+	reported := &MemoryReportedEndpoints{}
+
+	reporter := ChangeReporter{Source: StaticEndpoints{{EndpointID: "test-01"}, {EndpointID: "test-02"}}, Gateway: gw, Reported: reported}
+	assert.NoError(t, reporter.Report())
+
+	events = nil
+	restarted := ChangeReporter{Source: StaticEndpoints{{EndpointID: "test-01"}}, Gateway: gw, Reported: reported}
+	assert.NoError(t, restarted.Report())
+
+	if assert.Len(t, events, 1, "endpoints removed while the reporter was down should be deleted") {
+		assert.Equal(t, "DeleteReport", events[0].Event.Header.Name)
+	}
+}
@@ -8,10 +8,14 @@ import (
 	"github.com/betom84/go-alexa/smarthome/common/discoverable"
 )
 
-// Discovery uses the Endpoints file to process discovery directive
+// Discovery queries the endpoint source to process discovery directive
 type Discovery struct {
-	Endpoints            []discoverable.Endpoint
-	unmarshaledEndpoints []interface{}
+	Source EndpointSource
+
+	// Endpoints are discovered if Source is nil
+	//
+	// Deprecated: use Source with StaticEndpoints instead
+	Endpoints []discoverable.Endpoint
 }
 
 // IsCapable checks if an common.Directive is an discovery directive
@@ -25,17 +29,39 @@ func (d Discovery) Process(dir *common.Directive, device interface{}) (*common.R
 		return nil, fmt.Errorf("incompatible directive")
 	}
 
-	if d.Endpoints == nil {
+	source := d.Source
+	if source == nil && d.Endpoints != nil {
+		source = StaticEndpoints(d.Endpoints)
+	}
+
+	if source == nil {
 		return nil, fmt.Errorf("endpoints not specified")
 	}
 
+	var payload common.DiscoverPayload
+	if err := dir.DecodePayload(&payload); err != nil {
+		return nil, err
+	}
+
+	scope := payload.Scope
+	scope.Identity = dir.Identity
+
+	endpoints, err := source.Endpoints(scope)
+	if err != nil {
+		return nil, fmt.Errorf("could not query endpoints; %v", err)
+	}
+
+	if endpoints == nil {
+		endpoints = []discoverable.Endpoint{}
+	}
+
 	resp := new(common.Response)
 	resp.Event.Header = common.NewHeader("Discover.Response", "Alexa.Discovery")
 
 	resp.Event.Payload = struct {
 		Endpoints []discoverable.Endpoint `json:"endpoints"`
 	}{
-		Endpoints: d.Endpoints,
+		Endpoints: endpoints,
 	}
 
 	return resp, nil
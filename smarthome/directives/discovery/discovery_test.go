@@ -3,6 +3,7 @@ package discovery
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -35,12 +36,52 @@ func TestDiscovery(t *testing.T) {
 			directive:   helpers.CreateDirective(t, `{"header":{"namespace":"Not.Alexa.Discovery"}}`),
 			expectError: "incompatible directive",
 		},
+		{
+			name:        "it returns an error on discovery directive without scope",
+			processor:   createDiscovery(t, "testdata/endpoints.json"),
+			directive:   helpers.CreateDirective(t, `{"header":{"namespace":"Alexa.Discovery","name":"Discover"},"payload":{}}`),
+			expectError: "invalid payload field scope.type; must be BearerToken",
+		},
 		{
 			name:        "it returns an error when endpoints are undefined",
 			processor:   Discovery{},
 			directive:   helpers.LoadRequest(t, "testdata/request.json"),
 			expectError: "endpoints not specified",
 		},
+		{
+			name:       "it responds with deprecated endpoints without source",
+			processor:  Discovery{Endpoints: loadEndpoints(t, "testdata/endpoints.json")},
+			directive:  helpers.LoadRequest(t, "testdata/request.json"),
+			goldenFile: "testdata/response.json",
+		},
+		{
+			name:        "it returns an error when endpoint source fails",
+			processor:   Discovery{Source: EndpointSourceFunc(failingSource)},
+			directive:   helpers.LoadRequest(t, "testdata/request.json"),
+			expectError: "could not query endpoints; database is gone",
+		},
+		{
+			name:       "it responds with empty endpoints when source has none",
+			processor:  Discovery{Source: StaticEndpoints(nil)},
+			directive:  helpers.LoadRequest(t, "testdata/request.json"),
+			goldenFile: "testdata/empty_response.json",
+		},
+		{
+			name: "it queries the source with the discovery scope",
+			processor: Discovery{Source: Filter(loadEndpoints(t, "testdata/endpoints.json"), func(scope common.Scope, ep discoverable.Endpoint) bool {
+				return scope.Token == "access-token-from-skill"
+			})},
+			directive:  helpers.LoadRequest(t, "testdata/request.json"),
+			goldenFile: "testdata/response.json",
+		},
+		{
+			name: "it responds only with endpoints accepted by filter",
+			processor: Discovery{Source: Filter(loadEndpoints(t, "testdata/endpoints.json"), func(scope common.Scope, ep discoverable.Endpoint) bool {
+				return ep.EndpointID != "test-01"
+			})},
+			directive:  helpers.LoadRequest(t, "testdata/request.json"),
+			goldenFile: "testdata/empty_response.json",
+		},
 	}
 
 	for _, tc := range tt {
@@ -66,6 +107,14 @@ func TestDiscovery(t *testing.T) {
 }
 
 func createDiscovery(t *testing.T, endpoints string) Discovery {
+	return Discovery{Source: loadEndpoints(t, endpoints)}
+}
+
+func failingSource(scope common.Scope) ([]discoverable.Endpoint, error) {
+	return nil, fmt.Errorf("database is gone")
+}
+
+func loadEndpoints(t *testing.T, endpoints string) StaticEndpoints {
 	file, err := os.Open(endpoints)
 	if err != nil {
 		t.Fatal(err)
@@ -82,5 +131,5 @@ func createDiscovery(t *testing.T, endpoints string) Discovery {
 		t.Fatalf("could not unmarshal endpoints; %s", err)
 	}
 
-	return Discovery{Endpoints: diEp}
+	return StaticEndpoints(diEp)
 }
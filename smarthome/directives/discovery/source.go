@@ -0,0 +1,49 @@
+package discovery
+
+import (
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+)
+
+// EndpointSource provides the endpoints discoverable by alexa. It gets queried on each discover directive,
+// therefore endpoints can be loaded from anywhere (e.g. a database, a config file or a bridge).
+type EndpointSource interface {
+	// Endpoints returns the endpoints discoverable for the given scope. The scope holds the bearer token
+	// of the alexa user requesting discovery and may be used to filter endpoints per user.
+	Endpoints(scope common.Scope) ([]discoverable.Endpoint, error)
+}
+
+// StaticEndpoints is an EndpointSource which always provides the same endpoints
+type StaticEndpoints []discoverable.Endpoint
+
+// Endpoints returns all static endpoints regardless of the given scope
+func (s StaticEndpoints) Endpoints(scope common.Scope) ([]discoverable.Endpoint, error) {
+	return s, nil
+}
+
+// EndpointSourceFunc is an adapter to use ordinary functions as EndpointSource
+type EndpointSourceFunc func(scope common.Scope) ([]discoverable.Endpoint, error)
+
+// Endpoints calls f(scope)
+func (f EndpointSourceFunc) Endpoints(scope common.Scope) ([]discoverable.Endpoint, error) {
+	return f(scope)
+}
+
+// Filter creates an EndpointSource which only provides the endpoints of source accepted by the given filter function
+func Filter(source EndpointSource, accept func(scope common.Scope, endpoint discoverable.Endpoint) bool) EndpointSource {
+	return EndpointSourceFunc(func(scope common.Scope) ([]discoverable.Endpoint, error) {
+		endpoints, err := source.Endpoints(scope)
+		if err != nil {
+			return nil, err
+		}
+
+		filtered := []discoverable.Endpoint{}
+		for _, ep := range endpoints {
+			if accept(scope, ep) {
+				filtered = append(filtered, ep)
+			}
+		}
+
+		return filtered, nil
+	})
+}
@@ -37,6 +37,10 @@ func (c Controller) Process(dir *common.Directive, ed interface{}) (*common.Resp
 		return nil, common.NewInvalidDirectiveError("directive name should be TurnOn or TurnOff")
 	}
 
+	if err := dir.DecodePayload(&common.EmptyPayload{}); err != nil {
+		return nil, err
+	}
+
 	pd, ok := ed.(capabilities.PowerDevice)
 	if !ok {
 		return nil, fmt.Errorf("endpoint device does not support change of powerState")
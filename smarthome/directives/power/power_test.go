@@ -50,6 +50,12 @@ func TestController(t *testing.T) {
 			directive:   helpers.CreateDirective(t, `{"header":{"namespace":"Alexa.PowerController", "name":"Explode"}}`),
 			expectError: "directive name should be TurnOn or TurnOff",
 		},
+		{
+			name:        "it returns an error on unexpected payload",
+			directive:   helpers.CreateDirective(t, `{"header":{"namespace":"Alexa.PowerController", "name":"TurnOn"}, "payload":{"powerState":"ON"}}`),
+			device:      &mocks.MockPowerDevice{},
+			expectError: "invalid payload field powerState; is not expected",
+		},
 		{
 			name:        "it returns an error on incompatible directive namespace",
 			directive:   helpers.CreateDirective(t, `{"header":{"namespace":"Alexa.Whatever", "name":"TurnOn"}}`),
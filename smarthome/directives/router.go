@@ -0,0 +1,168 @@
+package directives
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+)
+
+// Route describes the directives a processor is registered for. Name and PayloadVersion are optional,
+// an empty value matches any name or version.
+type Route struct {
+	Namespace      string
+	Name           string
+	PayloadVersion string
+}
+
+func (r Route) String() string {
+	s := r.Namespace
+	if r.Name != "" {
+		s += "." + r.Name
+	}
+
+	if r.PayloadVersion != "" {
+		s += " (v" + r.PayloadVersion + ")"
+	}
+
+	return s
+}
+
+// Routable is implemented by directive processors which know the routes they are responsible for
+type Routable interface {
+	Routes() []Route
+}
+
+// Router looks up the directive processor by namespace, name and payload version of the directive. The
+// most specific route wins, routes are looked up in the following order:
+// namespace, name and version; namespace and name; namespace and version; namespace.
+// Processors without route are asked by IsCapable in the order they were added, if no route matches.
+// Routes must be registered before the router is used concurrently.
+type Router struct {
+	// Fallback processes directives without route, optional
+	Fallback DirectiveProcessor
+
+	routes     map[Route]DirectiveProcessor
+	processors []DirectiveProcessor
+}
+
+// Register the processor for the route, it returns an error if the route is already registered
+func (r *Router) Register(route Route, processor DirectiveProcessor) error {
+	if route.Namespace == "" {
+		return fmt.Errorf("route must contain a namespace")
+	}
+
+	if r.routes == nil {
+		r.routes = make(map[Route]DirectiveProcessor)
+	}
+
+	if _, exists := r.routes[route]; exists {
+		return fmt.Errorf("route %s is already registered", route)
+	}
+
+	if err := r.claimedByProcessor(route); err != nil {
+		return err
+	}
+
+	r.routes[route] = processor
+
+	return nil
+}
+
+// RegisterRoutable registers the processor for all of its routes, no route is registered if any of them
+// is already registered
+func (r *Router) RegisterRoutable(processor interface {
+	DirectiveProcessor
+	Routable
+}) error {
+	routes := processor.Routes()
+	for _, route := range routes {
+		if _, exists := r.routes[route]; exists {
+			return fmt.Errorf("route %s is already registered", route)
+		}
+
+		if err := r.claimedByProcessor(route); err != nil {
+			return err
+		}
+	}
+
+	for _, route := range routes {
+		err := r.Register(route, processor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Add a processor without route, it's asked by IsCapable if no route matches. Processors without route
+// come after all routes, so it returns an error and doesn't add the processor if it's capable of a
+// registered route, which would hide it.
+func (r *Router) Add(processor DirectiveProcessor) error {
+	routes := make([]Route, 0, len(r.routes))
+	for route := range r.routes {
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].String() < routes[j].String() })
+
+	for _, route := range routes {
+		if processor.IsCapable(routeDirective(route)) {
+			return fmt.Errorf("route %s is already registered, processor without route would never be asked", route)
+		}
+	}
+
+	r.processors = append(r.processors, processor)
+
+	return nil
+}
+
+// claimedByProcessor returns an error if a processor without route is capable of the route, the route
+// would hide the processor
+func (r *Router) claimedByProcessor(route Route) error {
+	for _, processor := range r.processors {
+		if processor.IsCapable(routeDirective(route)) {
+			return fmt.Errorf("route %s is claimed by a processor without route", route)
+		}
+	}
+
+	return nil
+}
+
+// routeDirective creates a directive matching the route to ask processors without route by IsCapable
+func routeDirective(route Route) *common.Directive {
+	return &common.Directive{
+		Header:  &common.Header{Namespace: route.Namespace, Name: route.Name, PayloadVersion: route.PayloadVersion},
+		Payload: map[string]interface{}{},
+	}
+}
+
+// Lookup the processor of the directive, the fallback is returned if neither a route matches nor
+// a processor without route is capable
+func (r *Router) Lookup(dir *common.Directive) (DirectiveProcessor, bool) {
+	if route, ok := r.Match(dir); ok {
+		return r.routes[route], true
+	}
+
+	for _, processor := range r.processors {
+		if processor.IsCapable(dir) {
+			return processor, true
+		}
+	}
+
+	return r.Fallback, r.Fallback != nil
+}
+
+// Match returns the most specific registered route matching the directive, processors without route
+// and the fallback are not taken into account
+func (r *Router) Match(dir *common.Directive) (Route, bool) {
+	ns, name, version := dir.Header.Namespace, dir.Header.Name, dir.Header.PayloadVersion
+
+	for _, route := range []Route{{ns, name, version}, {ns, name, ""}, {ns, "", version}, {ns, "", ""}} {
+		if _, ok := r.routes[route]; ok {
+			return route, true
+		}
+	}
+
+	return Route{}, false
+}
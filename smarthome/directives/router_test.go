@@ -0,0 +1,145 @@
+package directives_test
+
+import (
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/directives"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type routableProcessor struct {
+	mocks.MockDirectiveProcessor
+	routes []directives.Route
+}
+
+func (p *routableProcessor) Routes() []directives.Route {
+	return p.routes
+}
+
+func TestRouter(t *testing.T) {
+	namespace := &mocks.MockDirectiveProcessor{}
+	name := &mocks.MockDirectiveProcessor{}
+	version := &mocks.MockDirectiveProcessor{}
+	nameAndVersion := &mocks.MockDirectiveProcessor{}
+	fallback := &mocks.MockDirectiveProcessor{}
+
+	capable := &mocks.MockDirectiveProcessor{}
+	capable.On("IsCapable", mock.MatchedBy(func(dir *common.Directive) bool { return dir.Header.Namespace == "Legacy" })).Return(true)
+	capable.On("IsCapable", mock.Anything).Return(false)
+
+	router := directives.Router{}
+	assert.NoError(t, router.Register(directives.Route{Namespace: "Alexa.PowerController"}, namespace))
+	assert.NoError(t, router.Register(directives.Route{Namespace: "Alexa.PowerController", Name: "TurnOn"}, name))
+	assert.NoError(t, router.Register(directives.Route{Namespace: "Alexa.PowerController", PayloadVersion: "4"}, version))
+	assert.NoError(t, router.Register(directives.Route{Namespace: "Alexa.PowerController", Name: "TurnOn", PayloadVersion: "4"}, nameAndVersion))
+	assert.NoError(t, router.Add(capable))
+
+	tt := []struct {
+		name      string
+		header    common.Header
+		fallback  directives.DirectiveProcessor
+		expected  directives.DirectiveProcessor
+		expectNok bool
+		route     directives.Route
+	}{
+		{
+			name:     "it routes by namespace",
+			header:   common.Header{Namespace: "Alexa.PowerController", Name: "TurnOff", PayloadVersion: "3"},
+			expected: namespace,
+			route:    directives.Route{Namespace: "Alexa.PowerController"},
+		},
+		{
+			name:     "it routes by namespace and name",
+			header:   common.Header{Namespace: "Alexa.PowerController", Name: "TurnOn", PayloadVersion: "3"},
+			expected: name,
+			route:    directives.Route{Namespace: "Alexa.PowerController", Name: "TurnOn"},
+		},
+		{
+			name:     "it routes by namespace and version",
+			header:   common.Header{Namespace: "Alexa.PowerController", Name: "TurnOff", PayloadVersion: "4"},
+			expected: version,
+			route:    directives.Route{Namespace: "Alexa.PowerController", PayloadVersion: "4"},
+		},
+		{
+			name:     "it routes by namespace, name and version",
+			header:   common.Header{Namespace: "Alexa.PowerController", Name: "TurnOn", PayloadVersion: "4"},
+			expected: nameAndVersion,
+			route:    directives.Route{Namespace: "Alexa.PowerController", Name: "TurnOn", PayloadVersion: "4"},
+		},
+		{
+			name:     "it asks processors without route",
+			header:   common.Header{Namespace: "Legacy", Name: "Anything"},
+			expected: capable,
+		},
+		{
+			name:      "it returns nothing without route and fallback",
+			header:    common.Header{Namespace: "Unknown"},
+			expectNok: true,
+		},
+		{
+			name:     "it returns fallback without route",
+			header:   common.Header{Namespace: "Unknown"},
+			fallback: fallback,
+			expected: fallback,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			router.Fallback = tc.fallback
+
+			header := tc.header
+			processor, ok := router.Lookup(&common.Directive{Header: &header})
+			assert.Equal(t, !tc.expectNok, ok)
+			assert.True(t, tc.expected == processor, "unexpected processor")
+
+			route, ok := router.Match(&common.Directive{Header: &header})
+			assert.Equal(t, tc.route != directives.Route{}, ok)
+			assert.Equal(t, tc.route, route)
+		})
+	}
+}
+
+func TestRouterConflicts(t *testing.T) {
+	router := directives.Router{}
+
+	assert.EqualError(t, router.Register(directives.Route{Name: "TurnOn"}, &mocks.MockDirectiveProcessor{}), "route must contain a namespace")
+
+	assert.NoError(t, router.Register(directives.Route{Namespace: "Alexa.PowerController", Name: "TurnOn"}, &mocks.MockDirectiveProcessor{}))
+	assert.EqualError(t, router.Register(directives.Route{Namespace: "Alexa.PowerController", Name: "TurnOn"}, &mocks.MockDirectiveProcessor{}),
+		"route Alexa.PowerController.TurnOn is already registered")
+
+	p := &routableProcessor{routes: []directives.Route{{Namespace: "Alexa.PowerController", Name: "TurnOff"}, {Namespace: "Alexa.PowerController", Name: "TurnOn", PayloadVersion: "3"}}}
+	assert.NoError(t, router.RegisterRoutable(p))
+
+	p = &routableProcessor{routes: []directives.Route{{Namespace: "Alexa.ReportState"}, {Namespace: "Alexa.PowerController", Name: "TurnOff"}}}
+	assert.EqualError(t, router.RegisterRoutable(p), "route Alexa.PowerController.TurnOff is already registered")
+
+	_, ok := router.Lookup(&common.Directive{Header: &common.Header{Namespace: "Alexa.ReportState"}})
+	assert.False(t, ok, "no route of a conflicting processor should be registered")
+}
+
+func TestRouterConflictsWithProcessorsWithoutRoute(t *testing.T) {
+	power := &mocks.MockDirectiveProcessor{}
+	power.On("IsCapable", mock.MatchedBy(func(dir *common.Directive) bool { return dir.Header.Namespace == "Alexa.PowerController" })).Return(true)
+	power.On("IsCapable", mock.Anything).Return(false)
+
+	router := directives.Router{}
+	assert.NoError(t, router.Register(directives.Route{Namespace: "Alexa.PowerController"}, &mocks.MockDirectiveProcessor{}))
+	assert.EqualError(t, router.Add(power), "route Alexa.PowerController is already registered, processor without route would never be asked")
+
+	_, ok := router.Lookup(&common.Directive{Header: &common.Header{Namespace: "Other"}})
+	assert.False(t, ok, "conflicting processor should not be added")
+
+	router = directives.Router{}
+	assert.NoError(t, router.Add(power))
+	assert.EqualError(t, router.Register(directives.Route{Namespace: "Alexa.PowerController", Name: "TurnOn"}, &mocks.MockDirectiveProcessor{}),
+		"route Alexa.PowerController.TurnOn is claimed by a processor without route")
+	assert.EqualError(t, router.RegisterRoutable(&routableProcessor{routes: []directives.Route{{Namespace: "Alexa.PowerController"}}}),
+		"route Alexa.PowerController is claimed by a processor without route")
+	assert.NoError(t, router.Register(directives.Route{Namespace: "Alexa"}, &mocks.MockDirectiveProcessor{}))
+}
@@ -0,0 +1,180 @@
+// Package gateway provides the client to send events to the alexa event gateway
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/metrics"
+)
+
+// Endpoints of the alexa event gateway, use the one of the region your skill is deployed to
+const (
+	NorthAmerica = "https://api.amazonalexa.com/v3/events"
+	Europe       = "https://api.eu.amazonalexa.com/v3/events"
+	FarEast      = "https://api.fe.amazonalexa.com/v3/events"
+)
+
+// RefreshTokenURL to change for tests
+var RefreshTokenURL = "https://api.amazon.com/auth/o2/token"
+
+// Now is used to change the current time for tests, defaults to time.Now()
+var Now = time.Now
+
+// Gateway sends events on behalf of the linked alexa users
+type Gateway struct {
+	// URL of the event gateway, defaults to NorthAmerica
+	URL string
+
+	// Amazon client ID and secret used to refresh expired access tokens
+	ClientID     string
+	ClientSecret string
+
+	// Tokens of the linked alexa users
+	Tokens TokenStore
+
+	// Client to send http requests, defaults to http.DefaultClient
+	Client *http.Client
+
+	// Metrics to record sent events, optional
+	Metrics *metrics.Metrics
+}
+
+// Users returns all linked alexa users
+func (g *Gateway) Users() ([]string, error) {
+	if g.Tokens == nil {
+		return nil, fmt.Errorf("token store is missing")
+	}
+
+	return g.Tokens.Users()
+}
+
+// Scope returns the scope to send events for the given user, an expired access token gets refreshed
+func (g *Gateway) Scope(user string) (common.Scope, error) {
+	if g.Tokens == nil {
+		return common.Scope{}, fmt.Errorf("token store is missing")
+	}
+
+	token, err := g.Tokens.Token(user)
+	if err != nil {
+		return common.Scope{}, err
+	}
+
+	if token.Expired(Now()) {
+		token, err = g.refresh(user, token)
+		if err != nil {
+			return common.Scope{}, err
+		}
+	}
+
+	return common.Scope{Type: "BearerToken", Token: token.AccessToken}, nil
+}
+
+// GranteeScope returns the scope the given user got granted with, it's the same scope alexa sends along with directives
+func (g *Gateway) GranteeScope(user string) (common.Scope, error) {
+	if g.Tokens == nil {
+		return common.Scope{}, fmt.Errorf("token store is missing")
+	}
+
+	token, err := g.Tokens.Token(user)
+	if err != nil {
+		return common.Scope{}, err
+	}
+
+	return common.Scope{Type: "BearerToken", Token: token.GranteeToken}, nil
+}
+
+// Send the event to the event gateway authorized by the given scope
+func (g *Gateway) Send(scope common.Scope, event *common.Response) (err error) {
+	if event.Event.Header != nil {
+		defer func() { g.Metrics.ObserveGatewayEvent(event.Event.Header.Namespace, event.Event.Header.Name, err) }()
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest("POST", g.url(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", scope.Token))
+
+	response, err := g.client().Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusAccepted && response.StatusCode != http.StatusOK {
+		message, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("event gateway responded with %s; %s", response.Status, message)
+	}
+
+	return nil
+}
+
+func (g *Gateway) refresh(user string, token Token) (Token, error) {
+	if token.RefreshToken == "" {
+		return Token{}, fmt.Errorf("access token of user %s expired and can't be refreshed", user)
+	}
+
+	response, err := g.client().PostForm(RefreshTokenURL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {token.RefreshToken},
+		"client_id":     {g.ClientID},
+		"client_secret": {g.ClientSecret}})
+
+	if err != nil {
+		return Token{}, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return Token{}, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return Token{}, fmt.Errorf("could not refresh access token of user %s; %s", user, body)
+	}
+
+	var values map[string]interface{}
+	err = json.Unmarshal(body, &values)
+	if err != nil {
+		return Token{}, err
+	}
+
+	refreshed := NewToken(values, Now())
+	refreshed.GranteeToken = token.GranteeToken
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+
+	return refreshed, g.Tokens.Store(user, refreshed)
+}
+
+func (g *Gateway) url() string {
+	if g.URL == "" {
+		return NorthAmerica
+	}
+
+	return g.URL
+}
+
+func (g *Gateway) client() *http.Client {
+	if g.Client == nil {
+		return http.DefaultClient
+	}
+
+	return g.Client
+}
@@ -0,0 +1,122 @@
+package gateway_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/gateway"
+	"github.com/betom84/go-alexa/smarthome/metrics"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGatewaySend(t *testing.T) {
+	common.ConstMessageID = "any-const-message-id-for-test"
+
+	tt := []struct {
+		name          string
+		status        int
+		expectError   string
+		expectMetrics string
+	}{
+		{
+			name:          "it sends the event to the gateway",
+			status:        http.StatusAccepted,
+			expectMetrics: `alexa_gateway_events_total{namespace="Alexa.Discovery",name="AddOrUpdateReport",outcome="success"} 1`,
+		},
+		{
+			name:          "it returns an error when gateway rejects the event",
+			status:        http.StatusUnauthorized,
+			expectError:   "event gateway responded with 401 Unauthorized; {\"message\":\"denied\"}",
+			expectMetrics: `alexa_gateway_events_total{namespace="Alexa.Discovery",name="AddOrUpdateReport",outcome="error"} 1`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := &mocks.MockHTTPHandler{T: t}
+			handler.On("RequestURI", "POST", "/v3/events",
+				[]byte(`{"event":{"header":{"namespace":"Alexa.Discovery","name":"AddOrUpdateReport","messageId":"any-const-message-id-for-test","payloadVersion":"3"}}}`)).
+				Return([]byte(`{"message":"denied"}`), tc.status)
+			defer handler.AssertExpectations(t)
+
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			event := new(common.Response)
+			event.Event.Header = common.NewHeader("AddOrUpdateReport", "Alexa.Discovery")
+
+			m := metrics.New()
+			g := gateway.Gateway{URL: srv.URL + "/v3/events", Metrics: m}
+			err := g.Send(common.Scope{Type: "BearerToken", Token: "Atza|access"}, event)
+
+			var exposition bytes.Buffer
+			_, _ = m.WriteTo(&exposition)
+			assert.Contains(t, exposition.String(), tc.expectMetrics)
+
+			if len(tc.expectError) > 0 {
+				assert.EqualError(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGatewayScope(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-04-01T12:00:00+00:00")
+	gateway.Now = func() time.Time { return now }
+
+	handler := &mocks.MockHTTPHandler{T: t}
+	handler.On("RequestURI", "POST", "/auth/o2/token", mock.Anything).
+		Return([]byte(`{"access_token":"Atza|refreshed","token_type":"bearer","expires_in":3600}`), http.StatusOK)
+	defer handler.AssertExpectations(t)
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	gateway.RefreshTokenURL = srv.URL + "/auth/o2/token"
+
+	store := &gateway.MemoryTokenStore{}
+	_ = store.Store("valid@mail.com", gateway.Token{AccessToken: "Atza|valid", Expiry: now.Add(time.Minute)})
+	_ = store.Store("expired@mail.com", gateway.Token{AccessToken: "Atza|expired", RefreshToken: "Atzr|refresh", GranteeToken: "grantee", Expiry: now})
+	_ = store.Store("unrefreshable@mail.com", gateway.Token{AccessToken: "Atza|expired", Expiry: now})
+
+	g := gateway.Gateway{ClientID: "clientID", ClientSecret: "clientSecret", Tokens: store}
+
+	users, err := g.Users()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"expired@mail.com", "unrefreshable@mail.com", "valid@mail.com"}, users)
+
+	scope, err := g.Scope("valid@mail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, common.Scope{Type: "BearerToken", Token: "Atza|valid"}, scope)
+
+	scope, err = g.Scope("expired@mail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, common.Scope{Type: "BearerToken", Token: "Atza|refreshed"}, scope)
+
+	refreshed, _ := store.Token("expired@mail.com")
+	assert.Equal(t, "Atzr|refresh", refreshed.RefreshToken)
+	assert.Equal(t, "grantee", refreshed.GranteeToken)
+	assert.Equal(t, now.Add(time.Hour), refreshed.Expiry)
+
+	scope, err = g.GranteeScope("expired@mail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, common.Scope{Type: "BearerToken", Token: "grantee"}, scope)
+
+	_, err = g.Scope("unrefreshable@mail.com")
+	assert.EqualError(t, err, "access token of user unrefreshable@mail.com expired and can't be refreshed")
+
+	_, err = g.Scope("unknown@mail.com")
+	assert.EqualError(t, err, "no token stored for user unknown@mail.com")
+
+	_, err = (&gateway.Gateway{}).Scope("valid@mail.com")
+	assert.EqualError(t, err, "token store is missing")
+}
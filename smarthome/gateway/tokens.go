@@ -0,0 +1,98 @@
+package gateway
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Token holds the access information of an alexa user, granted to send events to the event gateway
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	Expiry       time.Time `json:"expiry"`
+
+	// GranteeToken is the bearer token the alexa user got granted with, it's the same token
+	// sent by alexa as scope of directives
+	GranteeToken string `json:"grantee_token"`
+}
+
+// Expired checks if the access token is expired at the given time
+func (t Token) Expired(now time.Time) bool {
+	return !t.Expiry.IsZero() && !now.Before(t.Expiry)
+}
+
+// NewToken creates a token from the values returned by an oauth token request
+func NewToken(values map[string]interface{}, now time.Time) Token {
+	t := Token{}
+	t.AccessToken, _ = values["access_token"].(string)
+	t.RefreshToken, _ = values["refresh_token"].(string)
+	t.TokenType, _ = values["token_type"].(string)
+
+	if expiresIn, ok := values["expires_in"].(float64); ok && expiresIn > 0 {
+		t.Expiry = now.Add(time.Duration(expiresIn) * time.Second)
+	}
+
+	return t
+}
+
+// TokenStore holds the tokens of all linked alexa users
+type TokenStore interface {
+	// Store saves the token of the given user, an existing token gets replaced
+	Store(user string, token Token) error
+
+	// Token returns the token of the given user
+	Token(user string) (Token, error)
+
+	// Users returns all users a token is stored for
+	Users() ([]string, error)
+}
+
+// MemoryTokenStore is a TokenStore which holds the tokens in memory only
+type MemoryTokenStore struct {
+	mutex  sync.RWMutex
+	tokens map[string]Token
+}
+
+// Store saves the token of the given user
+func (s *MemoryTokenStore) Store(user string, token Token) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.tokens == nil {
+		s.tokens = make(map[string]Token)
+	}
+
+	s.tokens[user] = token
+
+	return nil
+}
+
+// Token returns the token of the given user
+func (s *MemoryTokenStore) Token(user string) (Token, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	t, ok := s.tokens[user]
+	if !ok {
+		return Token{}, fmt.Errorf("no token stored for user %s", user)
+	}
+
+	return t, nil
+}
+
+// Users returns all users a token is stored for, sorted by name
+func (s *MemoryTokenStore) Users() ([]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	users := make([]string, 0, len(s.tokens))
+	for user := range s.tokens {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+
+	return users, nil
+}
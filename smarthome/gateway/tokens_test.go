@@ -0,0 +1,53 @@
+package gateway_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/gateway"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewToken(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-04-01T12:00:00+00:00")
+
+	token := gateway.NewToken(map[string]interface{}{
+		"access_token":  "Atza|access",
+		"refresh_token": "Atzr|refresh",
+		"token_type":    "bearer",
+		"expires_in":    float64(3600),
+	}, now)
+
+	assert.Equal(t, "Atza|access", token.AccessToken)
+	assert.Equal(t, "Atzr|refresh", token.RefreshToken)
+	assert.Equal(t, "bearer", token.TokenType)
+	assert.Equal(t, now.Add(time.Hour), token.Expiry)
+
+	assert.False(t, token.Expired(now))
+	assert.True(t, token.Expired(now.Add(time.Hour)))
+	assert.False(t, gateway.Token{}.Expired(now), "token without expiry should never expire")
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	store := gateway.MemoryTokenStore{}
+
+	users, err := store.Users()
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+
+	_, err = store.Token("somebody@mail.com")
+	assert.EqualError(t, err, "no token stored for user somebody@mail.com")
+
+	assert.NoError(t, store.Store("somebody@mail.com", gateway.Token{AccessToken: "first"}))
+	assert.NoError(t, store.Store("anybody@mail.com", gateway.Token{AccessToken: "second"}))
+	assert.NoError(t, store.Store("somebody@mail.com", gateway.Token{AccessToken: "third"}))
+
+	token, err := store.Token("somebody@mail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "third", token.AccessToken)
+
+	users, err = store.Users()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"anybody@mail.com", "somebody@mail.com"}, users)
+}
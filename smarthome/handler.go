@@ -3,15 +3,21 @@ package smarthome
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"runtime/debug"
 	"time"
 
 	"github.com/betom84/go-alexa/smarthome/common"
 	"github.com/betom84/go-alexa/smarthome/common/discoverable"
 	"github.com/betom84/go-alexa/smarthome/directives"
 	"github.com/betom84/go-alexa/smarthome/directives/authorization"
+	"github.com/betom84/go-alexa/smarthome/directives/discovery"
+	"github.com/betom84/go-alexa/smarthome/identity"
+	"github.com/betom84/go-alexa/smarthome/metrics"
+	"github.com/betom84/go-alexa/smarthome/redact"
 	"github.com/betom84/go-alexa/smarthome/validator"
 )
 
@@ -22,6 +28,15 @@ type DeviceFactory interface {
 	NewDevice(epType string, id string) (interface{}, error)
 }
 
+// UserDeviceFactory is a DeviceFactory which creates devices for the identity of an alexa user. It's used
+// instead of NewDevice if the scope of the directive got verified.
+type UserDeviceFactory interface {
+	DeviceFactory
+
+	// NewUserDevice creates a device for the given user, type and id
+	NewUserDevice(user *common.Identity, epType string, id string) (interface{}, error)
+}
+
 // Handler is a http server to handle alexa directives
 type Handler struct {
 	BasicAuth struct {
@@ -35,31 +50,113 @@ type Handler struct {
 	// Validator to ensure correct response formats, optional
 	Validator *validator.Validator
 
-	// Processors to handle directives
-	directiveProcessors []directives.DirectiveProcessor
+	// ScopeVerifier verifies the scope token of directives and resolves the identity of the alexa user, optional
+	ScopeVerifier identity.Verifier
+
+	// EndpointSource provides the discoverable endpoints, used to check conformance with the DeviceFactory
+	// and the ownership of endpoints in multi-tenant mode
+	EndpointSource discovery.EndpointSource
+
+	// MultiTenant mode partitions endpoints by the alexa user, directives for endpoints not provided to the
+	// user by the EndpointSource get rejected. Requires a ScopeVerifier.
+	MultiTenant bool
+
+	// Logger to log directive processing with fields like messageId and endpointId, defaults to the
+	// package logger Log
+	Logger StructuredLogger
+
+	// Redactor masks sensitive values before they get logged, defaults to redact.New()
+	Redactor *redact.Redactor
+
+	// Metrics of directive processing, optional
+	Metrics *metrics.Metrics
+
+	// OnPanic is called with the recovered value and stack trace if handling a directive panics, optional
+	OnPanic func(dir *common.Directive, recovered interface{}, stack []byte)
+
+	// OnHandled is called with the final response of each handled directive, including error responses of
+	// directives rejected before processing and of recovered panics, optional
+	OnHandled func(handled HandledDirective)
+
+	// Router to look up the processors of directives
+	router directives.Router
+
+	// Interceptors wrapping the processing of directives
+	interceptors []Interceptor
 }
 
 // NewDefaultHandler creates an instance to handle all supported alexa directives.
 func NewDefaultHandler(authority authorization.Authority, endpoints []discoverable.Endpoint) *Handler {
+	return NewDefaultHandlerWithSource(authority, discovery.StaticEndpoints(endpoints))
+}
+
+// NewDefaultHandlerWithSource creates an instance to handle all supported alexa directives, discoverable
+// endpoints are queried from the given source on each discovery directive.
+func NewDefaultHandlerWithSource(authority authorization.Authority, source discovery.EndpointSource) *Handler {
 	handler := new(Handler)
+	handler.EndpointSource = source
 
-	handler.AddDirectiveProcessor(directives.CreateAuthorizeDirectiveProcessor(authority))
-	handler.AddDirectiveProcessor(directives.CreateDiscoveryDirectiveProcessor(endpoints))
-	handler.AddDirectiveProcessor(directives.CreatePowerControllerDirectiveProcessor())
-	handler.AddDirectiveProcessor(directives.CreateReportAlexaDirectiveProcessor())
+	handler.mustHandle(directives.Route{Namespace: "Alexa.Authorization"}, directives.CreateAuthorizeDirectiveProcessor(authority))
+	handler.mustHandle(directives.Route{Namespace: "Alexa.Discovery"}, directives.CreateDynamicDiscoveryDirectiveProcessor(source))
+	handler.mustHandle(directives.Route{Namespace: "Alexa.PowerController"}, directives.CreatePowerControllerDirectiveProcessor())
+	handler.mustHandle(directives.Route{Namespace: "Alexa"}, directives.CreateReportAlexaDirectiveProcessor())
 
 	return handler
 }
 
-// AddDirectiveProcessor is used to add an directive processor to this instance
+// NewMultiTenantHandler creates an instance to handle all supported alexa directives for multiple users. Each user
+// only discovers and controls the endpoints configured by the authority, the scope of directives gets verified
+// by the given verifier to identify the user.
+func NewMultiTenantHandler(authority Authority, verifier identity.Verifier) *Handler {
+	handler := NewDefaultHandlerWithSource(authority, authority)
+	handler.ScopeVerifier = verifier
+	handler.MultiTenant = true
+
+	return handler
+}
+
+// AddDirectiveProcessor is used to add an directive processor to this instance. If the processor implements
+// directives.Routable it's registered for its routes and panics if any route is already registered, otherwise
+// it's asked by IsCapable for directives without matching route. It panics as well if such a processor is
+// capable of a registered route, because it would never be asked for those directives.
 func (h *Handler) AddDirectiveProcessor(processor directives.DirectiveProcessor) {
-	h.directiveProcessors = append(h.directiveProcessors, processor)
+	if routable, ok := processor.(interface {
+		directives.DirectiveProcessor
+		directives.Routable
+	}); ok {
+		if err := h.router.RegisterRoutable(routable); err != nil {
+			panic(fmt.Sprintf("smarthome: could not add directive processor; %v", err))
+		}
+		return
+	}
+
+	if err := h.router.Add(processor); err != nil {
+		panic(fmt.Sprintf("smarthome: could not add directive processor; %v", err))
+	}
+}
+
+// Handle registers the processor for directives matching the route, it returns an error if the route
+// is already registered
+func (h *Handler) Handle(route directives.Route, processor directives.DirectiveProcessor) error {
+	return h.router.Register(route, processor)
+}
+
+// HandleFallback sets the processor for directives without matching route, by default they are
+// answered with INVALID_DIRECTIVE
+func (h *Handler) HandleFallback(processor directives.DirectiveProcessor) {
+	h.router.Fallback = processor
+}
+
+func (h *Handler) mustHandle(route directives.Route, processor directives.DirectiveProcessor) {
+	if err := h.Handle(route, processor); err != nil {
+		panic(fmt.Sprintf("smarthome: %v", err))
+	}
 }
 
 // ServeHTTP is needed to satisfy the net/http.Handler interface. Therefore alexa.Handler can be used as http handler.
 func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	if ok := h.verifyBasicAuth(request); !ok {
-		Log.Warning("Unauthorized request rejected (User-Agent: %s, Remote-Addr: %s)\n", request.Header.Get("User-Agent"), request.RemoteAddr)
+		h.logger().Log(Warning, "Unauthorized request rejected", Any("userAgent", request.Header.Get("User-Agent")), Any("remoteAddr", request.RemoteAddr))
 
 		h.writeUnauthorizedHTTPResponse(writer)
 		return
@@ -96,9 +193,10 @@ func (h *Handler) validateOnDemand(payload []byte) {
 
 	validationStart := time.Now()
 	if err := h.Validator.Validate(payload); err != nil {
-		Log.Warning("Response validation failed\npayload:\n%s\nerrors:%s\n", string(payload), err)
+		h.Metrics.ValidationFailed()
+		h.logger().Log(Warning, "Response validation failed", Any("payload", string(payload)), Any("error", err))
 	} else {
-		Log.Info("Response validated without errors (Took: %.3f, Schema: %s)", time.Since(validationStart).Seconds(), h.Validator.SchemaReference)
+		h.logger().Log(Info, "Response validated without errors", Any("took", time.Since(validationStart).Seconds()), Any("schema", h.Validator.SchemaReference))
 	}
 }
 
@@ -118,7 +216,7 @@ func (h *Handler) writeUnauthorizedHTTPResponse(writer http.ResponseWriter) {
 }
 
 func (h *Handler) writeBadRequestHTTPResponse(writer http.ResponseWriter, err error) {
-	Log.Error("Unable to handle request,", err)
+	h.logger().Log(Error, "Unable to handle request", Any("error", err))
 
 	writer.WriteHeader(http.StatusBadRequest)
 	writer.Header().Set("Content-Type", "text/plain")
@@ -129,7 +227,7 @@ func (h *Handler) getDirectiveFromRequestBody(body []byte) (dir *common.Directiv
 	var raw map[string]*json.RawMessage
 	err = json.Unmarshal(body, &raw)
 	if err != nil {
-		Log.Error("%s; %s", err, body)
+		h.logger().Log(Error, "Unable to unmarshal request body", Any("error", err), Any("body", string(body)))
 		return nil, fmt.Errorf("Failed to unmarshal request body")
 	}
 
@@ -144,48 +242,169 @@ func (h *Handler) getDirectiveFromRequestBody(body []byte) (dir *common.Directiv
 
 func (h *Handler) handleDirective(dir *common.Directive) (r interface{}) {
 	var err error
+	var device interface{}
 
 	startTime := time.Now()
-	Log.Trace("Received directive %s", dir)
+	logger := h.logger().With(directiveFields(dir)...)
+	logger.Log(Trace, "Received directive")
 
-	for _, processor := range h.directiveProcessors {
-		if !processor.IsCapable(dir) {
-			continue
-		}
+	defer func() {
+		h.observeDirective(dir, device, r, time.Since(startTime))
+	}()
 
-		var device interface{}
-		if dir.Endpoint != nil {
-			device, err = h.DeviceFactory.NewDevice(dir.Endpoint.Cookie.Type, dir.Endpoint.Cookie.ID)
-			if err != nil {
-				Log.Error("Unable to create endpoint device (%v)", err)
-			}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			r = h.recoverDirective(logger, dir, recovered)
 		}
+	}()
+
+	if err = h.verifyScope(dir); err != nil {
+		logger.Log(Warning, "Unable to verify scope", Any("error", err))
+		r = h.createErrorResponse(dir, h.transformError(err))
+		return
+	}
+
+	if dir.Identity != nil {
+		logger = logger.With(Any("user", dir.Identity.Email))
+	}
+
+	if err = h.verifyEndpointOwnership(dir); err != nil {
+		logger.Log(Warning, "Rejected directive", Any("error", err))
+		r = h.createErrorResponse(dir, h.transformError(err))
+		return
+	}
+
+	processor, ok := h.router.Lookup(dir)
 
-		r, err = processor.Process(dir, device)
+	if ok && dir.Endpoint != nil {
+		device, err = h.newDevice(dir)
 		if err != nil {
-			r = h.createErrorResponse(dir, h.transformError(err))
-			Log.Error("%v", err)
+			logger.Log(Error, "Unable to create endpoint device", Any("error", err))
+			alexaErr := deviceAlexaError(err)
+			h.Metrics.DeviceFactoryFailed(alexaErr.Type)
+			r = h.createErrorResponse(dir, alexaErr)
+			return
 		}
+	}
 
-		Log.Trace("Processed %s in %.3fs", dir, time.Since(startTime).Seconds())
+	resp, err := h.intercept(dir, device, func(dir *common.Directive, device interface{}) (*common.Response, error) {
+		if !ok {
+			return nil, common.NewInvalidDirectiveError("Directive not supported")
+		}
 
-		return
+		return processor.Process(dir, device)
+	})
+
+	if err != nil {
+		r = h.createErrorResponse(dir, h.transformError(err))
+		logger.Log(Error, "Unable to process directive", Any("error", err))
+	} else {
+		r = resp
 	}
 
-	r = h.createErrorResponse(dir, common.NewInvalidDirectiveError("Directive not supported"))
+	logger.Log(Trace, "Processed directive", Any("took", time.Since(startTime).Seconds()))
+
 	return
 }
 
+func (h *Handler) observeDirective(dir *common.Directive, device interface{}, r interface{}, took time.Duration) {
+	if h.Metrics != nil {
+		// labels are taken from the matched route only, to keep the number of series bounded
+		namespace, name := metrics.Unknown, metrics.Unknown
+		if route, ok := h.router.Match(dir); ok {
+			namespace = route.Namespace
+			if route.Name != "" {
+				name = route.Name
+			}
+		}
+
+		h.Metrics.ObserveDirective(namespace, name, errorType(r), took)
+	}
+
+	if h.OnHandled != nil {
+		handled := HandledDirective{Directive: dir, Device: device, Took: took}
+		handled.Response, _ = r.(*common.Response)
+
+		if handled.Response != nil {
+			if payload, ok := handled.Response.Event.Payload.(errorPayload); ok {
+				handled.ErrorType, handled.Error = payload.Type, payload.Message
+			}
+		}
+
+		h.OnHandled(handled)
+	}
+}
+
+func (h *Handler) recoverDirective(logger StructuredLogger, dir *common.Directive, recovered interface{}) *common.Response {
+	stack := debug.Stack()
+	logger.Log(Error, "Recovered from panic while handling directive", Any("panic", recovered), Any("stack", string(stack)))
+
+	if h.OnPanic != nil {
+		h.OnPanic(dir, recovered, stack)
+	}
+
+	return h.createErrorResponse(dir, common.NewInternalError("unexpected error while processing directive"))
+}
+
+func (h *Handler) verifyScope(dir *common.Directive) error {
+	if h.ScopeVerifier == nil || dir.Header.Namespace == "Alexa.Authorization" {
+		return nil
+	}
+
+	scope, ok := dir.Scope()
+	if !ok {
+		return common.NewInvalidAuthorizationCredentialError("directive does not contain a scope")
+	}
+
+	user, err := h.ScopeVerifier.Verify(scope)
+	if errors.Is(err, identity.ErrInvalidToken) {
+		return common.NewInvalidAuthorizationCredentialError("scope token is invalid")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	dir.Identity = user
+	if dir.Endpoint != nil {
+		dir.Endpoint.Scope.Identity = user
+	}
+
+	return nil
+}
+
+func (h *Handler) verifyEndpointOwnership(dir *common.Directive) error {
+	if !h.MultiTenant || dir.Endpoint == nil {
+		return nil
+	}
+
+	if h.ScopeVerifier == nil || h.EndpointSource == nil {
+		return fmt.Errorf("multi-tenant mode requires a scope verifier and an endpoint source")
+	}
+
+	scope, _ := dir.Scope()
+	endpoints, err := h.EndpointSource.Endpoints(scope)
+	if err != nil {
+		return err
+	}
+
+	// the cookie is used to create the device, so it must not point to the device of another user
+	for _, ep := range endpoints {
+		if ep.EndpointID == dir.Endpoint.EndpointID && ep.Cookie.Type == dir.Endpoint.Cookie.Type && ep.Cookie.ID == dir.Endpoint.Cookie.ID {
+			return nil
+		}
+	}
+
+	return common.NewNoSuchEndpointError(fmt.Sprintf("endpoint %s does not exist", dir.Endpoint.EndpointID))
+}
+
 func (h *Handler) createErrorResponse(dir *common.Directive, err common.AlexaError) (resp *common.Response) {
 	resp = new(common.Response)
 	resp.Event.Header = common.NewHeader("ErrorResponse", err.Namespace)
 	resp.Event.Header.CorrelationToken = dir.Header.CorrelationToken
 	resp.Event.Endpoint = dir.Endpoint
 
-	resp.Event.Payload = struct {
-		Type    string `json:"type"`
-		Message string `json:"message"`
-	}{
+	resp.Event.Payload = errorPayload{
 		Type:    err.Type,
 		Message: err.Message,
 	}
@@ -193,10 +412,46 @@ func (h *Handler) createErrorResponse(dir *common.Directive, err common.AlexaErr
 	return
 }
 
+// HandledDirective describes a directive and the response it was answered with
+type HandledDirective struct {
+	Directive *common.Directive
+
+	// Device the directive was processed with, nil for directives without endpoint or if the device
+	// wasn't created
+	Device interface{}
+
+	// Response sent to alexa, an ErrorResponse if the directive failed
+	Response *common.Response
+
+	// ErrorType and Error are the type and message of the ErrorResponse, empty on success
+	ErrorType string
+	Error     string
+
+	// Took is the duration of handling the directive
+	Took time.Duration
+}
+
+type errorPayload struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func errorType(r interface{}) string {
+	if resp, ok := r.(*common.Response); ok && resp != nil {
+		if payload, ok := resp.Event.Payload.(errorPayload); ok {
+			return payload.Type
+		}
+	}
+
+	return ""
+}
+
 func (h *Handler) transformError(err error) common.AlexaError {
 	switch e := err.(type) {
 	case common.AlexaError:
 		return e
+	case common.PayloadError:
+		return e.AlexaError()
 	default:
 		return common.NewInternalError(e.Error())
 	}
@@ -6,11 +6,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
-	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/betom84/go-alexa/smarthome"
 	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/directives"
+	"github.com/betom84/go-alexa/smarthome/directives/discovery"
 	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
 	"github.com/betom84/go-alexa/smarthome/validator"
 
@@ -72,9 +74,20 @@ func TestAuthorization(t *testing.T) {
 
 func TestNewDefaultHandler(t *testing.T) {
 	handler := smarthome.NewDefaultHandler(nil, nil)
+	assertDefaultRoutes(t, handler)
+}
 
-	hv := reflect.ValueOf(*handler)
-	assert.Equal(t, 4, hv.FieldByName("directiveProcessors").Len())
+func TestNewDefaultHandlerWithSource(t *testing.T) {
+	handler := smarthome.NewDefaultHandlerWithSource(nil, discovery.StaticEndpoints{})
+	assertDefaultRoutes(t, handler)
+}
+
+func assertDefaultRoutes(t *testing.T, handler *smarthome.Handler) {
+	t.Helper()
+
+	for _, ns := range []string{"Alexa.Authorization", "Alexa.Discovery", "Alexa.PowerController", "Alexa"} {
+		assert.Error(t, handler.Handle(directives.Route{Namespace: ns}, &mocks.MockDirectiveProcessor{}), "expected route %s to be registered", ns)
+	}
 }
 
 func TestHandler(t *testing.T) {
@@ -137,6 +150,13 @@ func TestHandler(t *testing.T) {
 			mockDeviceFactory:      createMockDeviceFactory("testing", "ABC-123"),
 			expectedResponse:       readFile(t, "testdata/internal_error_response.json"),
 		},
+		{
+			name:                   "it responds with invalid directive on payload error returned by processor",
+			request:                []byte(`{"directive":{"header":{"namespace":"Registered.Processor"}}}`),
+			expectedStatusCode:     http.StatusOK,
+			mockDirectiveProcessor: createMockDirectiveProcessor(true, common.PayloadError{Field: "grant.code", Message: "is missing"}),
+			expectedResponse:       []byte(`{"event":{"header":{"namespace":"Alexa","name":"ErrorResponse","messageId":"any-const-message-id-for-test","payloadVersion":"3"},"payload":{"type":"INVALID_DIRECTIVE","message":"invalid payload field grant.code; is missing"}}}`),
+		},
 	}
 
 	for _, tc := range tt {
@@ -201,3 +221,42 @@ func readFile(t *testing.T, file string) []byte {
 
 	return bytes
 }
+
+type routableProcessor struct {
+	*mocks.MockDirectiveProcessor
+	routes []directives.Route
+}
+
+func (p routableProcessor) Routes() []directives.Route {
+	return p.routes
+}
+
+func TestHandlerRouting(t *testing.T) {
+	common.ConstMessageID = "any-const-message-id-for-test"
+
+	handler := smarthome.NewDefaultHandler(nil, nil)
+
+	assert.Panics(t, func() {
+		handler.AddDirectiveProcessor(routableProcessor{&mocks.MockDirectiveProcessor{}, []directives.Route{{Namespace: "Alexa.PowerController"}}})
+	}, "conflicting routes must be detected")
+
+	assert.Panics(t, func() {
+		handler.AddDirectiveProcessor(createMockDirectiveProcessor(true, nil))
+	}, "processors without route capable of a registered route must be detected")
+
+	turnOn := createMockDirectiveProcessor(true, nil)
+	handler.AddDirectiveProcessor(routableProcessor{turnOn, []directives.Route{{Namespace: "Alexa.PowerController", Name: "TurnOn"}}})
+
+	fallback := &mocks.MockDirectiveProcessor{}
+	fallback.On("Process", mock.Anything, mock.Anything).Return(&common.Response{}, common.NewInvalidDirectiveError("handled by fallback"))
+	handler.HandleFallback(fallback)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(`{"directive":{"header":{"namespace":"Alexa.PowerController","name":"TurnOn"}}}`)))
+	assert.JSONEq(t, `{"event":{"header":null}}`, rec.Body.String())
+	turnOn.AssertNumberOfCalls(t, "Process", 1)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(`{"directive":{"header":{"namespace":"Something.Unknown"}}}`)))
+	assert.Contains(t, rec.Body.String(), "handled by fallback")
+}
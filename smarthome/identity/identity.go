@@ -0,0 +1,165 @@
+// Package identity verifies the scope of directives and resolves the identity of the alexa user
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/gateway"
+)
+
+// ErrInvalidToken is returned by a Verifier if the scope token is not valid
+var ErrInvalidToken = errors.New("invalid token")
+
+// Now is used to change the current time for tests, defaults to time.Now()
+var Now = time.Now
+
+// Verifier verifies the bearer token of a scope and resolves the identity of the alexa user
+type Verifier interface {
+	// Verify returns the identity of the user the scope token belongs to, or ErrInvalidToken
+	// if the token is not valid
+	Verify(scope common.Scope) (*common.Identity, error)
+}
+
+// VerifierFunc is an adapter to use ordinary functions as Verifier
+type VerifierFunc func(scope common.Scope) (*common.Identity, error)
+
+// Verify calls f(scope)
+func (f VerifierFunc) Verify(scope common.Scope) (*common.Identity, error) {
+	return f(scope)
+}
+
+// TokenStoreVerifier accepts the tokens users got granted with, as stored by smarthome.Authority. Alexa refreshes
+// the access token of the account link (hourly with login with amazon) and sends the new token along with later
+// directives. Tokens unknown to the store are resolved by the Fallback verifier, the grantee token of the resolved
+// user gets updated if the user is linked. Without Fallback only non-expiring tokens are accepted after a refresh.
+//
+// Verify looks at the tokens of all users, use NewTokenStoreVerifier to cache resolved identities.
+type TokenStoreVerifier struct {
+	Tokens gateway.TokenStore
+
+	// Fallback resolves tokens unknown to the store, like refreshed tokens, e.g. an LWAVerifier
+	Fallback Verifier
+}
+
+// NewTokenStoreVerifier creates a verifier accepting the tokens of linked users, tokens unknown to the store are
+// resolved by fallback (optional). Resolved identities are cached for the given ttl.
+func NewTokenStoreVerifier(tokens gateway.TokenStore, fallback Verifier, ttl time.Duration) Verifier {
+	return NewCachingVerifier(TokenStoreVerifier{Tokens: tokens, Fallback: fallback}, ttl)
+}
+
+// Verify looks up the user the scope token was granted to
+func (v TokenStoreVerifier) Verify(scope common.Scope) (*common.Identity, error) {
+	if v.Tokens == nil {
+		return nil, fmt.Errorf("token store is missing")
+	}
+
+	if scope.Token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	users, err := v.Tokens.Users()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		token, err := v.Tokens.Token(user)
+		if err != nil {
+			return nil, err
+		}
+
+		if token.GranteeToken == scope.Token {
+			return &common.Identity{Email: user}, nil
+		}
+	}
+
+	if v.Fallback == nil {
+		return nil, ErrInvalidToken
+	}
+
+	return v.verifyRefreshed(scope)
+}
+
+// verifyRefreshed resolves the token by the fallback and accepts it, if the resolved user is linked
+func (v TokenStoreVerifier) verifyRefreshed(scope common.Scope) (*common.Identity, error) {
+	user, err := v.Fallback.Verify(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || user.Email == "" {
+		return nil, ErrInvalidToken
+	}
+
+	token, err := v.Tokens.Token(user.Email)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	token.GranteeToken = scope.Token
+	if err = v.Tokens.Store(user.Email, token); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CachingVerifier caches the identities resolved by another verifier, to avoid a remote lookup on each directive
+type CachingVerifier struct {
+	Verifier Verifier
+
+	// TTL of cached identities
+	TTL time.Duration
+
+	mutex sync.Mutex
+	cache map[string]cachedIdentity
+}
+
+type cachedIdentity struct {
+	identity *common.Identity
+	expiry   time.Time
+}
+
+// NewCachingVerifier creates a verifier which caches the identities resolved by the given verifier
+func NewCachingVerifier(verifier Verifier, ttl time.Duration) *CachingVerifier {
+	return &CachingVerifier{Verifier: verifier, TTL: ttl}
+}
+
+// Verify returns the cached identity of the scope token or resolves it
+func (v *CachingVerifier) Verify(scope common.Scope) (*common.Identity, error) {
+	now := Now()
+
+	v.mutex.Lock()
+	cached, ok := v.cache[scope.Token]
+	v.mutex.Unlock()
+
+	if ok && now.Before(cached.expiry) {
+		return cached.identity, nil
+	}
+
+	identity, err := v.Verifier.Verify(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.cache == nil {
+		v.cache = make(map[string]cachedIdentity)
+	}
+
+	for token, c := range v.cache {
+		if !now.Before(c.expiry) {
+			delete(v.cache, token)
+		}
+	}
+
+	v.cache[scope.Token] = cachedIdentity{identity: identity, expiry: now.Add(v.TTL)}
+
+	return identity, nil
+}
@@ -0,0 +1,102 @@
+package identity_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/gateway"
+	"github.com/betom84/go-alexa/smarthome/identity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenStoreVerifier(t *testing.T) {
+	store := &gateway.MemoryTokenStore{}
+	_ = store.Store("somebody@mail.com", gateway.Token{GranteeToken: "somebodys-token"})
+	_ = store.Store("anybody@mail.com", gateway.Token{GranteeToken: "anybodys-token"})
+
+	verifier := identity.TokenStoreVerifier{Tokens: store}
+
+	user, err := verifier.Verify(common.Scope{Type: "BearerToken", Token: "somebodys-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, &common.Identity{Email: "somebody@mail.com"}, user)
+
+	_, err = verifier.Verify(common.Scope{Type: "BearerToken", Token: "unknown-token"})
+	assert.Equal(t, identity.ErrInvalidToken, err)
+
+	_, err = verifier.Verify(common.Scope{})
+	assert.Equal(t, identity.ErrInvalidToken, err)
+
+	_, err = identity.TokenStoreVerifier{}.Verify(common.Scope{})
+	assert.EqualError(t, err, "token store is missing")
+}
+
+func TestTokenStoreVerifierRefreshedToken(t *testing.T) {
+	store := &gateway.MemoryTokenStore{}
+	_ = store.Store("somebody@mail.com", gateway.Token{AccessToken: "event-token", GranteeToken: "somebodys-token"})
+
+	fallback := identity.VerifierFunc(func(scope common.Scope) (*common.Identity, error) {
+		switch scope.Token {
+		case "somebodys-refreshed-token":
+			return &common.Identity{Email: "somebody@mail.com", Name: "Somebody"}, nil
+		case "strangers-token":
+			return &common.Identity{Email: "stranger@mail.com"}, nil
+		}
+		return nil, fmt.Errorf("profile lookup failed; %w", identity.ErrInvalidToken)
+	})
+
+	verifier := identity.TokenStoreVerifier{Tokens: store, Fallback: fallback}
+
+	user, err := verifier.Verify(common.Scope{Token: "somebodys-refreshed-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, "somebody@mail.com", user.Email)
+
+	token, _ := store.Token("somebody@mail.com")
+	assert.Equal(t, "somebodys-refreshed-token", token.GranteeToken, "refreshed token should be stored")
+	assert.Equal(t, "event-token", token.AccessToken)
+
+	_, err = verifier.Verify(common.Scope{Token: "strangers-token"})
+	assert.Equal(t, identity.ErrInvalidToken, err, "users without account link must not be accepted")
+
+	_, err = verifier.Verify(common.Scope{Token: "unknown-token"})
+	assert.True(t, errors.Is(err, identity.ErrInvalidToken))
+
+	cached := identity.NewTokenStoreVerifier(store, fallback, time.Minute)
+	user, err = cached.Verify(common.Scope{Token: "somebodys-refreshed-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, "somebody@mail.com", user.Email)
+}
+
+func TestCachingVerifier(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-04-01T12:00:00+00:00")
+	identity.Now = func() time.Time { return now }
+
+	calls := 0
+	verifier := identity.NewCachingVerifier(identity.VerifierFunc(func(scope common.Scope) (*common.Identity, error) {
+		calls++
+		if scope.Token == "invalid" {
+			return nil, identity.ErrInvalidToken
+		}
+		return &common.Identity{Email: fmt.Sprintf("%s@mail.com", scope.Token)}, nil
+	}), time.Minute)
+
+	for i := 0; i < 3; i++ {
+		user, err := verifier.Verify(common.Scope{Token: "somebody"})
+		assert.NoError(t, err)
+		assert.Equal(t, "somebody@mail.com", user.Email)
+	}
+	assert.Equal(t, 1, calls, "identity should be cached")
+
+	now = now.Add(time.Minute)
+	_, _ = verifier.Verify(common.Scope{Token: "somebody"})
+	assert.Equal(t, 2, calls, "expired identity should be resolved again")
+
+	for i := 0; i < 2; i++ {
+		_, err := verifier.Verify(common.Scope{Token: "invalid"})
+		assert.Equal(t, identity.ErrInvalidToken, err)
+	}
+	assert.Equal(t, 4, calls, "invalid tokens should not be cached")
+}
@@ -0,0 +1,77 @@
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+)
+
+// ProfileURL of login with amazon to change for tests
+var ProfileURL = "https://api.amazon.com/user/profile"
+
+// LWAVerifier resolves the identity by requesting the login with amazon profile of the scope token.
+// The scope profile must be requested in the account linking section of the skill.
+type LWAVerifier struct {
+	// Client to send http requests, defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// NewLWAVerifier creates a verifier requesting the login with amazon profile, resolved identities are cached for the given ttl
+func NewLWAVerifier(ttl time.Duration) Verifier {
+	return NewCachingVerifier(LWAVerifier{}, ttl)
+}
+
+// Verify requests the profile of the scope token
+func (v LWAVerifier) Verify(scope common.Scope) (*common.Identity, error) {
+	if scope.Token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	request, err := http.NewRequest("GET", ProfileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", scope.Token))
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrInvalidToken
+	default:
+		return nil, fmt.Errorf("could not request profile; %s %s", response.Status, body)
+	}
+
+	var profile struct {
+		UserID string `json:"user_id"`
+		Email  string `json:"email"`
+		Name   string `json:"name"`
+	}
+
+	err = json.Unmarshal(body, &profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.Identity{Email: profile.Email, UserID: profile.UserID, Name: profile.Name}, nil
+}
@@ -0,0 +1,72 @@
+package identity_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/identity"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLWAVerifier(t *testing.T) {
+	tt := []struct {
+		name        string
+		token       string
+		status      int
+		body        string
+		expected    *common.Identity
+		expectError string
+	}{
+		{
+			name:     "it resolves identity from profile",
+			token:    "valid",
+			status:   http.StatusOK,
+			body:     `{"user_id":"amzn1.account.K2LI23KL2LK2","email":"mhashimoto-04@plaxo.com","name":"Morio Hashimoto"}`,
+			expected: &common.Identity{Email: "mhashimoto-04@plaxo.com", UserID: "amzn1.account.K2LI23KL2LK2", Name: "Morio Hashimoto"},
+		},
+		{
+			name:        "it rejects invalid tokens",
+			token:       "invalid",
+			status:      http.StatusBadRequest,
+			body:        `{"error":"invalid_token","error_description":"The request has an invalid parameter : access_token"}`,
+			expectError: identity.ErrInvalidToken.Error(),
+		},
+		{
+			name:        "it returns an error when profile is not available",
+			token:       "valid",
+			status:      http.StatusServiceUnavailable,
+			body:        `maintenance`,
+			expectError: "could not request profile; 503 Service Unavailable maintenance",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := &mocks.MockHTTPHandler{T: t}
+			handler.On("RequestURI", "GET", "/user/profile", mock.Anything).Return([]byte(tc.body), tc.status)
+			defer handler.AssertExpectations(t)
+
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			identity.ProfileURL = srv.URL + "/user/profile"
+
+			user, err := identity.LWAVerifier{}.Verify(common.Scope{Type: "BearerToken", Token: tc.token})
+			if len(tc.expectError) > 0 {
+				assert.EqualError(t, err, tc.expectError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, user)
+		})
+	}
+
+	_, err := identity.NewLWAVerifier(0).Verify(common.Scope{})
+	assert.Equal(t, identity.ErrInvalidToken, err)
+}
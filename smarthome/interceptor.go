@@ -0,0 +1,90 @@
+package smarthome
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+)
+
+// Next continues processing the directive with the next interceptor or finally the directive processor
+type Next func(dir *common.Directive, device interface{}) (*common.Response, error)
+
+// Interceptor wraps the processing of directives. It may change the directive or device before calling next,
+// inspect the response or error returned by next, or short-circuit by returning an error (like an
+// common.AlexaError) without calling next. The device is nil for directives without endpoint.
+type Interceptor interface {
+	Intercept(dir *common.Directive, device interface{}, next Next) (*common.Response, error)
+}
+
+// InterceptorFunc is an adapter to use ordinary functions as Interceptor
+type InterceptorFunc func(dir *common.Directive, device interface{}, next Next) (*common.Response, error)
+
+// Intercept calls f(dir, device, next)
+func (f InterceptorFunc) Intercept(dir *common.Directive, device interface{}, next Next) (*common.Response, error) {
+	return f(dir, device, next)
+}
+
+// Use adds interceptors to the handler, they are called in the order they were added. Interceptors are called
+// for directives passing scope verification, after the device got created.
+func (h *Handler) Use(interceptors ...Interceptor) {
+	h.interceptors = append(h.interceptors, interceptors...)
+}
+
+func (h *Handler) intercept(dir *common.Directive, device interface{}, process Next) (*common.Response, error) {
+	next := process
+	for i := len(h.interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := h.interceptors[i], next
+		next = func(dir *common.Directive, device interface{}) (*common.Response, error) {
+			return interceptor.Intercept(dir, device, inner)
+		}
+	}
+
+	return next(dir, device)
+}
+
+// RequireIdentity rejects directives of unknown users with INVALID_AUTHORIZATION_CREDENTIAL, authorization
+// directives are passed. Requires a ScopeVerifier to resolve the identity.
+func RequireIdentity() Interceptor {
+	return InterceptorFunc(func(dir *common.Directive, device interface{}, next Next) (*common.Response, error) {
+		if dir.Identity == nil && dir.Header.Namespace != "Alexa.Authorization" {
+			return nil, common.NewInvalidAuthorizationCredentialError("identity of user is unknown")
+		}
+
+		return next(dir, device)
+	})
+}
+
+// Authorize calls the given function before processing a directive, the directive is answered with the returned
+// error if it's not nil. Use it to decide if a user is allowed to perform a directive at a device.
+func Authorize(authorize func(dir *common.Directive, device interface{}) error) Interceptor {
+	return InterceptorFunc(func(dir *common.Directive, device interface{}, next Next) (*common.Response, error) {
+		if err := authorize(dir, device); err != nil {
+			return nil, err
+		}
+
+		return next(dir, device)
+	})
+}
+
+// LogDirectives logs the outcome and duration of each processed directive with severity info to the package logger Log
+func LogDirectives() Interceptor {
+	return LogDirectivesTo(Redacting(FromLogger(nil), defaultRedactor))
+}
+
+// LogDirectivesTo logs the outcome and duration of each processed directive with severity info to the given logger
+func LogDirectivesTo(logger StructuredLogger) Interceptor {
+	return InterceptorFunc(func(dir *common.Directive, device interface{}, next Next) (*common.Response, error) {
+		start := time.Now()
+		resp, err := next(dir, device)
+
+		fields := append(directiveFields(dir), Any("device", fmt.Sprintf("%T", device)), Any("took", time.Since(start).Seconds()))
+		if err != nil {
+			logger.Log(Info, "Failed to process directive", append(fields, Any("error", err))...)
+		} else {
+			logger.Log(Info, "Processed directive", fields...)
+		}
+
+		return resp, err
+	})
+}
@@ -0,0 +1,108 @@
+package smarthome_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandlerInterceptors(t *testing.T) {
+	common.ConstMessageID = "any-const-message-id-for-test"
+
+	var calls []string
+	record := func(name string) smarthome.Interceptor {
+		return smarthome.InterceptorFunc(func(dir *common.Directive, device interface{}, next smarthome.Next) (*common.Response, error) {
+			calls = append(calls, name+" before "+device.(string))
+			resp, err := next(dir, device)
+			calls = append(calls, name+" after")
+			return resp, err
+		})
+	}
+
+	tt := []struct {
+		name             string
+		interceptors     []smarthome.Interceptor
+		expectedCalls    []string
+		expectedResponse string
+		processed        bool
+	}{
+		{
+			name:             "it calls interceptors in order",
+			interceptors:     []smarthome.Interceptor{record("first"), record("second")},
+			expectedCalls:    []string{"first before device", "second before device", "second after", "first after"},
+			expectedResponse: `{"event":{"header":null}}`,
+			processed:        true,
+		},
+		{
+			name: "it lets interceptors change the device",
+			interceptors: []smarthome.Interceptor{
+				smarthome.InterceptorFunc(func(dir *common.Directive, device interface{}, next smarthome.Next) (*common.Response, error) {
+					return next(dir, "changed device")
+				}),
+				record("second"),
+			},
+			expectedCalls:    []string{"second before changed device", "second after"},
+			expectedResponse: `{"event":{"header":null}}`,
+			processed:        true,
+		},
+		{
+			name: "it lets interceptors short-circuit",
+			interceptors: []smarthome.Interceptor{
+				record("first"),
+				smarthome.Authorize(func(dir *common.Directive, device interface{}) error {
+					return common.NewNoSuchEndpointError("not yours")
+				}),
+				record("third"),
+			},
+			expectedCalls:    []string{"first before device", "first after"},
+			expectedResponse: "NO_SUCH_ENDPOINT",
+		},
+		{
+			name:             "it rejects directives of unknown users",
+			interceptors:     []smarthome.Interceptor{smarthome.RequireIdentity()},
+			expectedResponse: "INVALID_AUTHORIZATION_CREDENTIAL",
+		},
+		{
+			name:             "it logs directives",
+			interceptors:     []smarthome.Interceptor{smarthome.LogDirectives()},
+			expectedResponse: `{"event":{"header":null}}`,
+			processed:        true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			calls = nil
+
+			f := &mocks.MockDeviceFactory{}
+			f.On("NewDevice", "testing", "ABC-123").Return("device", nil)
+
+			p := createMockDirectiveProcessor(true, nil)
+
+			handler := smarthome.Handler{DeviceFactory: f}
+			handler.AddDirectiveProcessor(p)
+			handler.Use(tc.interceptors...)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(readFile(t, "testdata/process_directive_request.json"))))
+			body, _ := ioutil.ReadAll(rec.Result().Body)
+
+			assert.Equal(t, tc.expectedCalls, calls)
+			assert.Contains(t, string(body), tc.expectedResponse)
+
+			if tc.processed {
+				p.AssertCalled(t, "Process", mock.Anything, mock.Anything)
+			} else {
+				p.AssertNotCalled(t, "Process", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
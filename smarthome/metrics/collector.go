@@ -0,0 +1,201 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets of latency histograms in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type family struct {
+	name   string
+	help   string
+	labels []string
+}
+
+func (f family) writeHeader(w io.Writer, kind string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, kind)
+	return err
+}
+
+func (f family) labelPairs(values []string, extra ...string) string {
+	pairs := make([]string, 0, len(values)+1)
+	for i, value := range values {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", f.labels[i], escapeLabel(value)))
+	}
+
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", extra[i], escapeLabel(extra[i+1])))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// counterVec is a counter partitioned by label values
+type counterVec struct {
+	family
+
+	mutex  sync.Mutex
+	values map[string]float64
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{family: family{name, help, labels}, values: make(map[string]float64)}
+}
+
+func (c *counterVec) inc(labelValues ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.values[joinLabels(labelValues)]++
+}
+
+func (c *counterVec) writeTo(w io.Writer) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	err := c.writeHeader(w, "counter")
+	if err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(c.values) {
+		_, err = fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(splitLabels(key, len(c.labels))), formatFloat(c.values[key]))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// histogramVec is a histogram partitioned by label values
+type histogramVec struct {
+	family
+	buckets []float64
+
+	mutex  sync.Mutex
+	values map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{family: family{name, help, labels}, buckets: buckets, values: make(map[string]*histogram)}
+}
+
+func (h *histogramVec) observe(value float64, labelValues ...string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	key := joinLabels(labelValues)
+	hist, ok := h.values[key]
+	if !ok {
+		hist = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hist
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			hist.counts[i]++
+		}
+	}
+
+	hist.count++
+	hist.sum += value
+}
+
+func (h *histogramVec) writeTo(w io.Writer) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	err := h.writeHeader(w, "histogram")
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(h.values))
+	for key := range h.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		hist, labels := h.values[key], splitLabels(key, len(h.labels))
+
+		for i, bound := range h.buckets {
+			_, err = fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(labels, "le", formatFloat(bound)), hist.counts[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = fmt.Fprintf(w, "%s_bucket%s %d\n%s_sum%s %s\n%s_count%s %d\n",
+			h.name, h.labelPairs(labels, "le", "+Inf"), hist.count,
+			h.name, h.labelPairs(labels), formatFloat(hist.sum),
+			h.name, h.labelPairs(labels), hist.count)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const labelSeparator = "\xff"
+
+func joinLabels(values []string) string {
+	return strings.Join(values, labelSeparator)
+}
+
+func splitLabels(key string, count int) []string {
+	if count == 0 {
+		return nil
+	}
+
+	return strings.Split(key, labelSeparator)
+}
+
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelReplacer.Replace(value)
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(value string) string {
+	return helpReplacer.Replace(value)
+}
@@ -0,0 +1,113 @@
+// Package metrics collects metrics of directive processing and serves them in the prometheus text exposition format
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Unknown is the namespace and name label of directives whose route doesn't declare them, it keeps the number
+// of series bounded although alexa or anybody else may send arbitrary directives
+const Unknown = "unknown"
+
+// Metrics of directive processing. All methods are safe for concurrent use and can be called
+// on a nil instance, which records nothing.
+type Metrics struct {
+	directives         *counterVec
+	directiveDuration  *histogramVec
+	deviceFailures     *counterVec
+	validationFailures *counterVec
+	gatewayEvents      *counterVec
+}
+
+// New creates metrics with latencies recorded in the given histogram buckets, DefaultBuckets are used if none are given
+func New(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	return &Metrics{
+		directives: newCounterVec("alexa_directives_total",
+			"Directives handled by namespace, name, outcome and error type.", "namespace", "name", "outcome", "error_type"),
+		directiveDuration: newHistogramVec("alexa_directive_duration_seconds",
+			"Latency of directive handling in seconds.", buckets, "namespace", "name"),
+		deviceFailures: newCounterVec("alexa_device_factory_failures_total",
+			"Devices the device factory failed to create by error type.", "error_type"),
+		validationFailures: newCounterVec("alexa_response_validation_failures_total",
+			"Responses failing the schema validation."),
+		gatewayEvents: newCounterVec("alexa_gateway_events_total",
+			"Events sent to the alexa event gateway by namespace, name and outcome.", "namespace", "name", "outcome"),
+	}
+}
+
+// ObserveDirective records a handled directive, errorType is the alexa error type or empty on success. Pass Unknown
+// as namespace and name unless they are declared by the route of the directive.
+func (m *Metrics) ObserveDirective(namespace, name, errorType string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.directives.inc(namespace, name, outcome(errorType == ""), errorType)
+	m.directiveDuration.observe(duration.Seconds(), namespace, name)
+}
+
+// DeviceFactoryFailed records a device the device factory failed to create
+func (m *Metrics) DeviceFactoryFailed(errorType string) {
+	if m == nil {
+		return
+	}
+
+	m.deviceFailures.inc(errorType)
+}
+
+// ValidationFailed records a response failing the schema validation
+func (m *Metrics) ValidationFailed() {
+	if m == nil {
+		return
+	}
+
+	m.validationFailures.inc()
+}
+
+// ObserveGatewayEvent records an event sent to the alexa event gateway
+func (m *Metrics) ObserveGatewayEvent(namespace, name string, err error) {
+	if m == nil {
+		return
+	}
+
+	m.gatewayEvents.inc(namespace, name, outcome(err == nil))
+}
+
+// WriteTo writes all metrics in the prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	if m == nil {
+		return 0, nil
+	}
+
+	var buffer bytes.Buffer
+
+	for _, collector := range []interface{ writeTo(io.Writer) error }{
+		m.directives, m.directiveDuration, m.deviceFailures, m.validationFailures, m.gatewayEvents} {
+		if err := collector.writeTo(&buffer); err != nil {
+			return 0, err
+		}
+	}
+
+	return buffer.WriteTo(w)
+}
+
+// ServeHTTP serves all metrics in the prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+func outcome(success bool) string {
+	if success {
+		return "success"
+	}
+
+	return "error"
+}
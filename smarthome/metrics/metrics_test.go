@@ -0,0 +1,55 @@
+package metrics_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/metrics"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New(0.1, 1)
+
+	m.ObserveDirective("Alexa.PowerController", "TurnOn", "", 50*time.Millisecond)
+	m.ObserveDirective("Alexa.PowerController", "TurnOn", "", 500*time.Millisecond)
+	m.ObserveDirective("Alexa.PowerController", "TurnOn", "ENDPOINT_UNREACHABLE", 2*time.Second)
+	m.DeviceFactoryFailed("ENDPOINT_UNREACHABLE")
+	m.ValidationFailed()
+	m.ObserveGatewayEvent("Alexa.Discovery", "AddOrUpdateReport", nil)
+	m.ObserveGatewayEvent("Alexa.Discovery", "DeleteReport", errors.New("gateway unavailable"))
+	m.ObserveGatewayEvent("Alexa.Discovery", "Escaped \"name\"\n", nil)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	expected, err := ioutil.ReadFile("testdata/metrics.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, string(expected), rec.Body.String())
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *metrics.Metrics
+
+	assert.NotPanics(t, func() {
+		m.ObserveDirective("Alexa", "ReportState", "", time.Second)
+		m.DeviceFactoryFailed("NO_SUCH_ENDPOINT")
+		m.ValidationFailed()
+		m.ObserveGatewayEvent("Alexa", "ChangeReport", nil)
+
+		_, err := m.WriteTo(ioutil.Discard)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		assert.Empty(t, rec.Body.String())
+	})
+}
@@ -0,0 +1,67 @@
+package smarthome_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/directives"
+	"github.com/betom84/go-alexa/smarthome/metrics"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandlerMetrics(t *testing.T) {
+	m := metrics.New()
+
+	f := &mocks.MockDeviceFactory{}
+	f.On("NewDevice", "testing", "ABC-123").Return("device", nil).Once()
+	f.On("NewDevice", "testing", "ABC-123").Return(nil, smarthome.NewDeviceUnreachableError("device is offline")).Once()
+
+	handler := smarthome.Handler{DeviceFactory: f, Metrics: m}
+	handler.AddDirectiveProcessor(routableProcessor{createMockDirectiveProcessor(true, nil), []directives.Route{{Namespace: "Something.Processable", Name: "DoIt"}}})
+	handler.AddDirectiveProcessor(routableProcessor{createMockDirectiveProcessor(true, nil), []directives.Route{{Namespace: "Registered.Processor"}}})
+
+	legacy := &mocks.MockDirectiveProcessor{}
+	legacy.On("IsCapable", mock.MatchedBy(func(dir *common.Directive) bool { return dir.Header.Namespace == "Made.Up" })).Return(true)
+	legacy.On("IsCapable", mock.Anything).Return(false)
+	legacy.On("Process", mock.Anything, mock.Anything).Return(&common.Response{}, nil)
+	handler.AddDirectiveProcessor(legacy)
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", bytes.NewReader(readFile(t, "testdata/process_directive_request.json"))))
+	}
+
+	for _, dir := range []string{
+		`{"directive":{"header":{"namespace":"Registered.Processor","name":"MadeUpName"}}}`,
+		`{"directive":{"header":{"namespace":"Made.Up","name":"First"}}}`,
+		`{"directive":{"header":{"namespace":"Made.Up","name":"Second"}}}`,
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(dir)))
+	}
+
+	var exposition bytes.Buffer
+	_, err := m.WriteTo(&exposition)
+	assert.NoError(t, err)
+
+	for _, line := range []string{
+		`alexa_directives_total{namespace="Something.Processable",name="DoIt",outcome="success",error_type=""} 1`,
+		`alexa_directives_total{namespace="Something.Processable",name="DoIt",outcome="error",error_type="ENDPOINT_UNREACHABLE"} 1`,
+		`alexa_directives_total{namespace="Registered.Processor",name="unknown",outcome="success",error_type=""} 1`,
+		`alexa_directives_total{namespace="unknown",name="unknown",outcome="success",error_type=""} 2`,
+		`alexa_directive_duration_seconds_count{namespace="Something.Processable",name="DoIt"} 2`,
+		`alexa_device_factory_failures_total{error_type="ENDPOINT_UNREACHABLE"} 1`,
+	} {
+		assert.Contains(t, exposition.String(), fmt.Sprintln(line))
+	}
+
+	for _, label := range []string{"MadeUpName", "Made.Up", "testing"} {
+		assert.NotContains(t, exposition.String(), label, "labels must be bounded")
+	}
+}
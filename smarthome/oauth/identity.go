@@ -0,0 +1,33 @@
+package oauth
+
+import (
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/directives/authorization"
+	"github.com/betom84/go-alexa/smarthome/identity"
+)
+
+// Verify accepts access tokens issued by the server, so it can be used as scope verifier of the smarthome.Handler
+func (s *Server) Verify(scope common.Scope) (*common.Identity, error) {
+	user, ok := s.Identity(scope.Token)
+	if !ok {
+		return nil, identity.ErrInvalidToken
+	}
+
+	return user, nil
+}
+
+// IdentityProvider returns the provider to accept grants of users linked by the server. The profile of
+// the grantee is resolved by the server, the grant code is still exchanged with login with amazon to
+// receive the tokens for the alexa event gateway.
+func (s *Server) IdentityProvider() authorization.IdentityProvider {
+	return serverProvider{LWA: authorization.LWA{}, server: s}
+}
+
+type serverProvider struct {
+	authorization.LWA
+	server *Server
+}
+
+func (p serverProvider) Profile(granteeToken string) (*common.Identity, error) {
+	return p.server.Verify(common.Scope{Token: granteeToken})
+}
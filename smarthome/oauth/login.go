@@ -0,0 +1,59 @@
+package oauth
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// LoginRequest contains the parameters of the authorization request, which must be posted back to the
+// authorize endpoint along with the username and password fields
+type LoginRequest struct {
+	ClientID     string
+	RedirectURI  string
+	State        string
+	Scope        string
+	ResponseType string
+
+	// Error of the previous login attempt, empty on the first attempt
+	Error string
+}
+
+// Fields returns the hidden form fields of the authorization request
+func (r LoginRequest) Fields() map[string]string {
+	return map[string]string{
+		"client_id":     r.ClientID,
+		"redirect_uri":  r.RedirectURI,
+		"state":         r.State,
+		"scope":         r.Scope,
+		"response_type": r.ResponseType,
+	}
+}
+
+// LoginPage renders the login form, the form must post the fields username and password together with
+// the fields of the login request to the authorize endpoint
+type LoginPage func(w http.ResponseWriter, r *http.Request, login LoginRequest)
+
+var loginTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Link account</title></head>
+<body>
+<form method="post">
+{{range $name, $value := .Fields}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}{{with .Error}}<p>{{.}}</p>
+{{end}}<p><input type="text" name="username" placeholder="Username" autofocus></p>
+<p><input type="password" name="password" placeholder="Password"></p>
+<p><button type="submit">Link account</button></p>
+</form>
+</body>
+</html>
+`))
+
+// DefaultLoginPage renders a plain login form
+func DefaultLoginPage(w http.ResponseWriter, r *http.Request, login LoginRequest) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if login.Error != "" {
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+
+	_ = loginTemplate.Execute(w, login)
+}
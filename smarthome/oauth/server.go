@@ -0,0 +1,346 @@
+// Package oauth contains an oauth2 authorization server to link alexa accounts without a third party provider,
+// it supports the authorization code grant and the refresh token grant
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+)
+
+// Now is used to change the current time for tests, defaults to time.Now()
+var Now = time.Now
+
+const (
+	defaultCodeTTL         = 5 * time.Minute
+	defaultAccessTokenTTL  = time.Hour
+	defaultRefreshTokenTTL = 90 * 24 * time.Hour
+)
+
+// Server is an oauth2 authorization server to mount next to the smarthome.Handler. It serves the
+// authorize and token endpoints for requests with path ending on "/authorize" and "/token".
+type Server struct {
+	// ClientID of the alexa skill, as configured in the account linking section
+	ClientID string
+
+	// ClientSecret of the alexa skill, as configured in the account linking section
+	ClientSecret string
+
+	// RedirectURIs allowed, as listed in the account linking section of the alexa skill
+	RedirectURIs []string
+
+	// Users to authenticate
+	Users UserStore
+
+	// LoginPage renders the login form, defaults to DefaultLoginPage
+	LoginPage LoginPage
+
+	// CodeTTL is the lifetime of authorization codes, defaults to 5 minutes
+	CodeTTL time.Duration
+
+	// AccessTokenTTL is the lifetime of access tokens, defaults to 1 hour
+	AccessTokenTTL time.Duration
+
+	// RefreshTokenTTL is the lifetime of refresh tokens, it's extended on each use. Defaults to 90 days.
+	RefreshTokenTTL time.Duration
+
+	// Tokens keeps the issued tokens, defaults to a MemoryTokenStore
+	Tokens TokenStore
+
+	mutex sync.Mutex
+}
+
+// ServeHTTP serves the authorize and token endpoints
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/authorize"):
+		s.serveAuthorize(w, r)
+	case strings.HasSuffix(r.URL.Path, "/token"):
+		s.serveToken(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveAuthorize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	login := LoginRequest{
+		ClientID:     r.Form.Get("client_id"),
+		RedirectURI:  r.Form.Get("redirect_uri"),
+		State:        r.Form.Get("state"),
+		Scope:        r.Form.Get("scope"),
+		ResponseType: r.Form.Get("response_type"),
+	}
+
+	if login.ClientID != s.ClientID {
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	}
+
+	if !s.allowedRedirectURI(login.RedirectURI) {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	if login.ResponseType != "code" {
+		s.redirect(w, r, login, url.Values{"error": {"unsupported_response_type"}})
+		return
+	}
+
+	loginPage := s.LoginPage
+	if loginPage == nil {
+		loginPage = DefaultLoginPage
+	}
+
+	if r.Method == http.MethodGet {
+		loginPage(w, r, login)
+		return
+	}
+
+	if s.Users == nil {
+		s.redirect(w, r, login, url.Values{"error": {"server_error"}})
+		return
+	}
+
+	user, err := s.Users.Authenticate(r.PostForm.Get("username"), r.PostForm.Get("password"))
+	if err == ErrInvalidCredentials {
+		login.Error = "Invalid username or password."
+		loginPage(w, r, login)
+		return
+	}
+
+	if err != nil || user == nil {
+		s.redirect(w, r, login, url.Values{"error": {"server_error"}})
+		return
+	}
+
+	code, err := s.issue(AuthorizationCode, Grant{Identity: user, RedirectURI: login.RedirectURI, Expiry: Now().Add(s.codeTTL())})
+	if err != nil {
+		s.redirect(w, r, login, url.Values{"error": {"server_error"}})
+		return
+	}
+
+	s.redirect(w, r, login, url.Values{"code": {code}})
+}
+
+func (s *Server) redirect(w http.ResponseWriter, r *http.Request, login LoginRequest, values url.Values) {
+	if login.State != "" {
+		values.Set("state", login.State)
+	}
+
+	separator := "?"
+	if strings.Contains(login.RedirectURI, "?") {
+		separator = "&"
+	}
+
+	http.Redirect(w, r, login.RedirectURI+separator+values.Encode(), http.StatusFound)
+}
+
+func (s *Server) allowedRedirectURI(uri string) bool {
+	for _, allowed := range s.RedirectURIs {
+		if uri == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		writeTokenError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	if clientID != s.ClientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(s.ClientSecret)) != 1 {
+		writeTokenError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	var identity *common.Identity
+	var refreshToken string
+
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		g, ok, redeemErr := s.redeem(AuthorizationCode, r.PostForm.Get("code"))
+		if redeemErr != nil {
+			writeTokenError(w, http.StatusInternalServerError, "server_error")
+			return
+		}
+
+		if !ok || g.RedirectURI != r.PostForm.Get("redirect_uri") {
+			writeTokenError(w, http.StatusBadRequest, "invalid_grant")
+			return
+		}
+
+		identity = g.Identity
+		refreshToken, err = s.issue(RefreshToken, Grant{Identity: identity, Expiry: Now().Add(s.refreshTokenTTL())})
+
+	case "refresh_token":
+		refreshToken = r.PostForm.Get("refresh_token")
+		g, ok, lookupErr := s.lookup(RefreshToken, refreshToken)
+		if lookupErr != nil {
+			writeTokenError(w, http.StatusInternalServerError, "server_error")
+			return
+		}
+
+		if !ok {
+			writeTokenError(w, http.StatusBadRequest, "invalid_grant")
+			return
+		}
+
+		// the refresh token stays valid as long as it's used regularly
+		identity = g.Identity
+		g.Expiry = Now().Add(s.refreshTokenTTL())
+		err = s.tokens().Save(RefreshToken, refreshToken, g)
+
+	default:
+		writeTokenError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	if err != nil {
+		writeTokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	accessToken, err := s.issue(AccessToken, Grant{Identity: identity, Expiry: Now().Add(s.accessTokenTTL())})
+	if err != nil {
+		writeTokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token":  accessToken,
+		"token_type":    "bearer",
+		"expires_in":    int(s.accessTokenTTL().Seconds()),
+		"refresh_token": refreshToken,
+	})
+}
+
+func writeTokenError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+// Identity returns the identity of the user the access token was issued to
+func (s *Server) Identity(accessToken string) (*common.Identity, bool) {
+	g, ok, err := s.lookup(AccessToken, accessToken)
+	if err != nil || !ok {
+		return nil, false
+	}
+
+	return g.Identity, true
+}
+
+// Revoke all tokens issued to the user with the given e-mail address
+func (s *Server) Revoke(email string) error {
+	return s.tokens().DeleteUser(email)
+}
+
+func (s *Server) tokens() TokenStore {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.Tokens == nil {
+		s.Tokens = &MemoryTokenStore{}
+	}
+
+	return s.Tokens
+}
+
+func (s *Server) issue(kind TokenKind, g Grant) (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("could not generate token; %v", err)
+	}
+
+	token := hex.EncodeToString(b)
+
+	return token, s.tokens().Save(kind, token, g)
+}
+
+func (s *Server) redeem(kind TokenKind, token string) (Grant, bool, error) {
+	if token == "" {
+		return Grant{}, false, nil
+	}
+
+	g, ok, err := s.tokens().Take(kind, token)
+	if err != nil {
+		return Grant{}, false, err
+	}
+
+	return g, ok && !g.Expired(Now()), nil
+}
+
+func (s *Server) lookup(kind TokenKind, token string) (Grant, bool, error) {
+	if token == "" {
+		return Grant{}, false, nil
+	}
+
+	g, ok, err := s.tokens().Load(kind, token)
+	if err != nil {
+		return Grant{}, false, err
+	}
+
+	return g, ok && !g.Expired(Now()), nil
+}
+
+func (s *Server) codeTTL() time.Duration {
+	if s.CodeTTL > 0 {
+		return s.CodeTTL
+	}
+
+	return defaultCodeTTL
+}
+
+func (s *Server) accessTokenTTL() time.Duration {
+	if s.AccessTokenTTL > 0 {
+		return s.AccessTokenTTL
+	}
+
+	return defaultAccessTokenTTL
+}
+
+func (s *Server) refreshTokenTTL() time.Duration {
+	if s.RefreshTokenTTL > 0 {
+		return s.RefreshTokenTTL
+	}
+
+	return defaultRefreshTokenTTL
+}
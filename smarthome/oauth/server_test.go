@@ -0,0 +1,339 @@
+package oauth_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/identity"
+	"github.com/betom84/go-alexa/smarthome/oauth"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectURI = "https://pitangui.amazon.com/api/skill/link/M2AAAAAAAAAAAA"
+
+var somebody = &common.Identity{Email: "somebody@mail.com", Name: "Some Body"}
+
+func createServer() *oauth.Server {
+	return &oauth.Server{
+		ClientID:     "clientID",
+		ClientSecret: "clientSecret",
+		RedirectURIs: []string{redirectURI},
+		Users: oauth.UserStoreFunc(func(username string, password string) (*common.Identity, error) {
+			if username == "somebody" && password == "secret" {
+				return somebody, nil
+			}
+
+			return nil, oauth.ErrInvalidCredentials
+		}),
+	}
+}
+
+func authorize(t *testing.T, srv *oauth.Server, method string, values url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var r *http.Request
+	if method == "GET" {
+		r = httptest.NewRequest(method, "/oauth/authorize?"+values.Encode(), nil)
+	} else {
+		r = httptest.NewRequest(method, "/oauth/authorize", strings.NewReader(values.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, r)
+
+	return rec
+}
+
+func token(t *testing.T, srv *oauth.Server, values url.Values) (int, map[string]interface{}) {
+	t.Helper()
+
+	r := httptest.NewRequest("POST", "/oauth/token", strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.SetBasicAuth("clientID", "clientSecret")
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, r)
+
+	var body map[string]interface{}
+	err := json.NewDecoder(rec.Body).Decode(&body)
+	if err != nil {
+		t.Fatalf("could not decode token response; %v", err)
+	}
+
+	return rec.Code, body
+}
+
+func login(t *testing.T, srv *oauth.Server) string {
+	t.Helper()
+
+	rec := authorize(t, srv, "POST", url.Values{
+		"client_id":     {"clientID"},
+		"redirect_uri":  {redirectURI},
+		"response_type": {"code"},
+		"state":         {"xyz"},
+		"username":      {"somebody"},
+		"password":      {"secret"},
+	})
+
+	if rec.Code != http.StatusFound {
+		t.Fatalf("expected redirect; got %d", rec.Code)
+	}
+
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "xyz", location.Query().Get("state"))
+	return location.Query().Get("code")
+}
+
+func TestAuthorize(t *testing.T) {
+	valid := url.Values{"client_id": {"clientID"}, "redirect_uri": {redirectURI}, "response_type": {"code"}, "state": {"xyz"}}
+
+	tt := []struct {
+		name             string
+		method           string
+		values           url.Values
+		expectedStatus   int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{
+			name:           "it renders the login page",
+			method:         "GET",
+			values:         valid,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `<input type="hidden" name="state" value="xyz">`,
+		},
+		{
+			name:           "it rejects unknown clients",
+			method:         "GET",
+			values:         url.Values{"client_id": {"other"}, "redirect_uri": {redirectURI}, "response_type": {"code"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "unknown client",
+		},
+		{
+			name:           "it rejects unknown redirect uris",
+			method:         "GET",
+			values:         url.Values{"client_id": {"clientID"}, "redirect_uri": {"https://example.com"}, "response_type": {"code"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid redirect_uri",
+		},
+		{
+			name:             "it redirects unsupported response types",
+			method:           "GET",
+			values:           url.Values{"client_id": {"clientID"}, "redirect_uri": {redirectURI}, "response_type": {"token"}, "state": {"xyz"}},
+			expectedStatus:   http.StatusFound,
+			expectedLocation: redirectURI + "?error=unsupported_response_type&state=xyz",
+		},
+		{
+			name:   "it renders the login page again on invalid credentials",
+			method: "POST",
+			values: url.Values{"client_id": {"clientID"}, "redirect_uri": {redirectURI}, "response_type": {"code"},
+				"username": {"somebody"}, "password": {"wrong"}},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Invalid username or password.",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := authorize(t, createServer(), tc.method, tc.values)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+			assert.Equal(t, tc.expectedLocation, rec.Header().Get("Location"))
+		})
+	}
+}
+
+func TestLoginPageHook(t *testing.T) {
+	srv := createServer()
+	srv.LoginPage = func(w http.ResponseWriter, r *http.Request, login oauth.LoginRequest) {
+		_, _ = w.Write([]byte("custom login for " + login.State))
+	}
+
+	rec := authorize(t, srv, "GET", url.Values{"client_id": {"clientID"}, "redirect_uri": {redirectURI}, "response_type": {"code"}, "state": {"xyz"}})
+	assert.Equal(t, "custom login for xyz", rec.Body.String())
+}
+
+func TestTokenFlow(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	oauth.Now = func() time.Time { return now }
+	defer func() { oauth.Now = time.Now }()
+
+	srv := createServer()
+	code := login(t, srv)
+
+	status, body := token(t, srv, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {"https://example.com"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"], "code must be bound to redirect uri")
+
+	code = login(t, srv)
+	status, body = token(t, srv, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {redirectURI}})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "bearer", body["token_type"])
+	assert.Equal(t, float64(3600), body["expires_in"])
+
+	accessToken := body["access_token"].(string)
+	refreshToken := body["refresh_token"].(string)
+
+	user, err := srv.Verify(common.Scope{Type: "BearerToken", Token: accessToken})
+	assert.NoError(t, err)
+	assert.Equal(t, somebody, user)
+
+	status, body = token(t, srv, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {redirectURI}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"], "code must be redeemed once only")
+
+	now = now.Add(time.Hour)
+	_, err = srv.Verify(common.Scope{Type: "BearerToken", Token: accessToken})
+	assert.Equal(t, identity.ErrInvalidToken, err)
+
+	status, body = token(t, srv, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, refreshToken, body["refresh_token"])
+
+	profile, err := srv.IdentityProvider().Profile(body["access_token"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, somebody, profile)
+
+	assert.NoError(t, srv.Revoke("somebody@mail.com"))
+	status, body = token(t, srv, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+}
+
+func TestTokenErrors(t *testing.T) {
+	srv := createServer()
+
+	r := httptest.NewRequest("POST", "/oauth/token", strings.NewReader("grant_type=authorization_code&code=any&client_id=clientID&client_secret=wrong"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, r)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.JSONEq(t, `{"error":"invalid_client"}`, rec.Body.String())
+
+	status, body := token(t, srv, url.Values{"grant_type": {"password"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "unsupported_grant_type", body["error"])
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/oauth/other", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestRefreshTokenExpiry(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	oauth.Now = func() time.Time { return now }
+	defer func() { oauth.Now = time.Now }()
+
+	srv := createServer()
+	srv.RefreshTokenTTL = 24 * time.Hour
+
+	_, body := token(t, srv, url.Values{"grant_type": {"authorization_code"}, "code": {login(t, srv)}, "redirect_uri": {redirectURI}})
+	refreshToken := body["refresh_token"].(string)
+
+	now = now.Add(23 * time.Hour)
+	status, _ := token(t, srv, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+	assert.Equal(t, http.StatusOK, status, "refresh token must be extended on use")
+
+	now = now.Add(23 * time.Hour)
+	status, _ = token(t, srv, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+	assert.Equal(t, http.StatusOK, status)
+
+	now = now.Add(24 * time.Hour)
+	status, body = token(t, srv, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+}
+
+func TestTokenStore(t *testing.T) {
+	tokens := &oauth.MemoryTokenStore{}
+
+	srv := createServer()
+	srv.Tokens = tokens
+
+	_, body := token(t, srv, url.Values{"grant_type": {"authorization_code"}, "code": {login(t, srv)}, "redirect_uri": {redirectURI}})
+
+	restarted := createServer()
+	restarted.Tokens = tokens
+
+	user, err := restarted.Verify(common.Scope{Type: "BearerToken", Token: body["access_token"].(string)})
+	assert.NoError(t, err)
+	assert.Equal(t, somebody, user)
+
+	status, _ := token(t, restarted, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {body["refresh_token"].(string)}})
+	assert.Equal(t, http.StatusOK, status)
+
+	g, ok, err := tokens.Load(oauth.RefreshToken, body["refresh_token"].(string))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, somebody, g.Identity)
+}
+
+type failingTokenStore struct {
+	oauth.MemoryTokenStore
+}
+
+func (s *failingTokenStore) Load(kind oauth.TokenKind, token string) (oauth.Grant, bool, error) {
+	return oauth.Grant{}, false, errors.New("database unavailable")
+}
+
+func (s *failingTokenStore) Take(kind oauth.TokenKind, token string) (oauth.Grant, bool, error) {
+	return oauth.Grant{}, false, errors.New("database unavailable")
+}
+
+func TestConcurrentCodeRedemption(t *testing.T) {
+	srv := createServer()
+	code := login(t, srv)
+
+	var wg sync.WaitGroup
+	statuses := make(chan int, 10)
+
+	for i := 0; i < cap(statuses); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, _ := token(t, srv, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {redirectURI}})
+			statuses <- status
+		}()
+	}
+
+	wg.Wait()
+	close(statuses)
+
+	granted := 0
+	for status := range statuses {
+		if status == http.StatusOK {
+			granted++
+		} else {
+			assert.Equal(t, http.StatusBadRequest, status)
+		}
+	}
+
+	assert.Equal(t, 1, granted, "code must be redeemed once only")
+}
+
+func TestTokenStoreErrors(t *testing.T) {
+	srv := createServer()
+	srv.Tokens = &failingTokenStore{}
+
+	status, body := token(t, srv, url.Values{"grant_type": {"authorization_code"}, "code": {login(t, srv)}, "redirect_uri": {redirectURI}})
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "server_error", body["error"])
+
+	_, err := srv.Verify(common.Scope{Type: "BearerToken", Token: "any"})
+	assert.Equal(t, identity.ErrInvalidToken, err)
+}
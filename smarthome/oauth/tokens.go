@@ -0,0 +1,132 @@
+package oauth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+)
+
+// TokenKind distinguishes the tokens issued by the server
+type TokenKind string
+
+// Kinds of tokens issued by the server
+const (
+	AuthorizationCode TokenKind = "authorization_code"
+	AccessToken       TokenKind = "access_token"
+	RefreshToken      TokenKind = "refresh_token"
+)
+
+// Grant is what the server knows about an issued token
+type Grant struct {
+	// Identity of the user the token was issued to
+	Identity *common.Identity
+
+	// RedirectURI the authorization code was issued for, empty for other tokens
+	RedirectURI string
+
+	// Expiry of the token
+	Expiry time.Time
+}
+
+// Expired checks if the token is expired at the given time
+func (g Grant) Expired(now time.Time) bool {
+	return !g.Expiry.IsZero() && !now.Before(g.Expiry)
+}
+
+// TokenStore keeps the tokens issued by the server. Implement it to keep account links across restarts of the
+// server, e.g. within a database. Expired grants may be returned by Load, they are rejected by the server.
+type TokenStore interface {
+	// Save the grant of the token, an existing grant gets replaced
+	Save(kind TokenKind, token string, grant Grant) error
+
+	// Load the grant of the token, ok is false for unknown tokens
+	Load(kind TokenKind, token string) (grant Grant, ok bool, err error)
+
+	// Take loads and deletes the grant of the token in one step, ok is false for unknown tokens. It must be atomic,
+	// so authorization codes can be redeemed once only, even by concurrent requests.
+	Take(kind TokenKind, token string) (grant Grant, ok bool, err error)
+
+	// Delete the token
+	Delete(kind TokenKind, token string) error
+
+	// DeleteUser deletes all tokens issued to the user with the given e-mail address
+	DeleteUser(email string) error
+}
+
+// MemoryTokenStore is a TokenStore which holds the tokens in memory only, users need to link their account
+// again after the server restarted
+type MemoryTokenStore struct {
+	mutex  sync.Mutex
+	tokens map[TokenKind]map[string]Grant
+}
+
+// Save the grant of the token, expired tokens get removed
+func (s *MemoryTokenStore) Save(kind TokenKind, token string, grant Grant) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.tokens == nil {
+		s.tokens = make(map[TokenKind]map[string]Grant)
+	}
+
+	if s.tokens[kind] == nil {
+		s.tokens[kind] = make(map[string]Grant)
+	}
+
+	now := Now()
+	for t, g := range s.tokens[kind] {
+		if g.Expired(now) {
+			delete(s.tokens[kind], t)
+		}
+	}
+
+	s.tokens[kind][token] = grant
+
+	return nil
+}
+
+// Load the grant of the token
+func (s *MemoryTokenStore) Load(kind TokenKind, token string) (Grant, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	g, ok := s.tokens[kind][token]
+	return g, ok, nil
+}
+
+// Take loads and deletes the grant of the token
+func (s *MemoryTokenStore) Take(kind TokenKind, token string) (Grant, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	g, ok := s.tokens[kind][token]
+	delete(s.tokens[kind], token)
+
+	return g, ok, nil
+}
+
+// Delete the token
+func (s *MemoryTokenStore) Delete(kind TokenKind, token string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.tokens[kind], token)
+	return nil
+}
+
+// DeleteUser deletes all tokens issued to the user
+func (s *MemoryTokenStore) DeleteUser(email string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, tokens := range s.tokens {
+		for t, g := range tokens {
+			if g.Identity != nil && g.Identity.Email == email {
+				delete(tokens, t)
+			}
+		}
+	}
+
+	return nil
+}
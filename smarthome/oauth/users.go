@@ -0,0 +1,25 @@
+package oauth
+
+import (
+	"errors"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+)
+
+// ErrInvalidCredentials is returned by a UserStore if username or password are wrong
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// UserStore authenticates the users logging in to link their account
+type UserStore interface {
+	// Authenticate returns the identity of the user, or ErrInvalidCredentials. The e-mail address of
+	// the identity is used to grant access by the smarthome.Authority.
+	Authenticate(username string, password string) (*common.Identity, error)
+}
+
+// UserStoreFunc is an adapter to use ordinary functions as UserStore
+type UserStoreFunc func(username string, password string) (*common.Identity, error)
+
+// Authenticate calls f(username, password)
+func (f UserStoreFunc) Authenticate(username string, password string) (*common.Identity, error) {
+	return f(username, password)
+}
@@ -0,0 +1,68 @@
+package smarthome_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandlerRecoversPanics(t *testing.T) {
+	common.ConstMessageID = "any-const-message-id-for-test"
+
+	tt := []struct {
+		name          string
+		deviceFactory smarthome.DeviceFactory
+		processor     *mocks.MockDirectiveProcessor
+	}{
+		{
+			name:          "it recovers panics of processors",
+			deviceFactory: createMockDeviceFactory("testing", "ABC-123"),
+			processor: func() *mocks.MockDirectiveProcessor {
+				p := &mocks.MockDirectiveProcessor{}
+				p.On("IsCapable", mock.Anything).Return(true)
+				p.On("Process", mock.Anything, mock.Anything).Run(func(mock.Arguments) { panic("something horrible") })
+				return p
+			}(),
+		},
+		{
+			name: "it recovers panics of device factories",
+			deviceFactory: func() smarthome.DeviceFactory {
+				f := &mocks.MockDeviceFactory{}
+				f.On("NewDevice", "testing", "ABC-123").Run(func(mock.Arguments) { panic("something horrible") })
+				return f
+			}(),
+			processor: createMockDirectiveProcessor(true, nil),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var panicked *common.Directive
+			var recovered interface{}
+
+			handler := smarthome.Handler{DeviceFactory: tc.deviceFactory}
+			handler.AddDirectiveProcessor(tc.processor)
+			handler.OnPanic = func(dir *common.Directive, r interface{}, stack []byte) {
+				panicked, recovered = dir, r
+				assert.Contains(t, string(stack), "smarthome.(*Handler).handleDirective")
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(readFile(t, "testdata/process_directive_request.json"))))
+
+			body, _ := ioutil.ReadAll(rec.Result().Body)
+			assert.JSONEq(t, string(readFile(t, "testdata/panic_response.json")), string(body))
+
+			assert.Equal(t, "appliance-001", panicked.Endpoint.EndpointID)
+			assert.Equal(t, "something horrible", recovered)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Package redact masks sensitive values of alexa messages, like bearer tokens, grant codes and pins,
+// before they get logged
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces redacted values
+const Mask = "[REDACTED]"
+
+// DefaultPaths of sensitive values, a path matches every JSON field whose path ends with it
+var DefaultPaths = []string{
+	"scope.token",
+	"grant.code",
+	"grantee.token",
+	"authorization.value",
+	"pin",
+	"client_secret",
+	"clientSecret",
+	"access_token",
+	"refresh_token",
+	"password",
+}
+
+var bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[^\s"',;]+`)
+
+// Redactor masks sensitive values, it's safe for concurrent use
+type Redactor struct {
+	paths      [][]string
+	keyPattern *regexp.Regexp
+}
+
+// New creates a redactor masking the DefaultPaths and the given extra paths, like "payload.secret"
+func New(extra ...string) *Redactor {
+	r := &Redactor{}
+
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, path := range append(append([]string{}, DefaultPaths...), extra...) {
+		segments := strings.Split(path, ".")
+		r.paths = append(r.paths, segments)
+
+		name := segments[len(segments)-1]
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, regexp.QuoteMeta(name))
+		}
+	}
+
+	// matches "name": "value" of malformed json as well as name=value of forms
+	r.keyPattern = regexp.MustCompile(fmt.Sprintf(`("(?:%[1]s)"\s*:\s*)"(?:[^"\\]|\\.)*"|\b((?:%[1]s)=)[^&\s]*`, strings.Join(names, "|")))
+
+	return r
+}
+
+// JSON masks the sensitive values of a JSON document, malformed documents are masked by String
+func (r *Redactor) JSON(data []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil || decoder.More() {
+		return []byte(r.String(string(data)))
+	}
+
+	redacted, err := json.Marshal(r.walk(nil, document))
+	if err != nil {
+		return []byte(r.String(string(data)))
+	}
+
+	return redacted
+}
+
+// String masks the sensitive values of a text, which may contain JSON, form values or bearer tokens
+func (r *Redactor) String(s string) string {
+	trimmed := strings.TrimSpace(s)
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return string(r.JSON([]byte(trimmed)))
+	}
+
+	s = r.keyPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := r.keyPattern.FindStringSubmatch(match)
+		if groups[1] != "" {
+			return groups[1] + `"` + Mask + `"`
+		}
+
+		return groups[2] + Mask
+	})
+
+	return bearerPattern.ReplaceAllString(s, "${1}"+Mask)
+}
+
+// Field masks the value of a log field, if the key is sensitive or the value contains sensitive data
+func (r *Redactor) Field(key string, value interface{}) interface{} {
+	if r.matches([]string{key}) {
+		return Mask
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.String(v)
+	case []byte:
+		return r.String(string(v))
+	case json.RawMessage:
+		return json.RawMessage(r.JSON(v))
+	case error:
+		redacted := r.String(v.Error())
+		if redacted != v.Error() {
+			return redacted
+		}
+	case fmt.Stringer:
+		redacted := r.String(v.String())
+		if redacted != v.String() {
+			return redacted
+		}
+	}
+
+	return value
+}
+
+func (r *Redactor) walk(path []string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := append(path[:len(path):len(path)], key)
+			if r.matches(childPath) {
+				v[key] = Mask
+			} else {
+				v[key] = r.walk(childPath, child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.walk(path, child)
+		}
+	case string:
+		return bearerPattern.ReplaceAllString(v, "${1}"+Mask)
+	}
+
+	return value
+}
+
+func (r *Redactor) matches(path []string) bool {
+	for _, rule := range r.paths {
+		if len(rule) > len(path) {
+			continue
+		}
+
+		matched := true
+		for i := range rule {
+			if !strings.EqualFold(rule[len(rule)-1-i], path[len(path)-1-i]) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,85 @@
+package redact_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome/redact"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSON(t *testing.T) {
+	tt := []struct {
+		name     string
+		input    string
+		expected string
+		extra    []string
+	}{
+		{
+			name:     "it masks the endpoint scope token",
+			input:    `{"directive":{"endpoint":{"scope":{"type":"BearerToken","token":"Atza|secret"},"endpointId":"appliance-001"}}}`,
+			expected: `{"directive":{"endpoint":{"scope":{"type":"BearerToken","token":"[REDACTED]"},"endpointId":"appliance-001"}}}`,
+		},
+		{
+			name:     "it masks grant code and grantee token",
+			input:    `{"directive":{"payload":{"grant":{"type":"OAuth2.AuthorizationCode","code":"secret"},"grantee":{"type":"BearerToken","token":"secret"}}}}`,
+			expected: `{"directive":{"payload":{"grant":{"type":"OAuth2.AuthorizationCode","code":"[REDACTED]"},"grantee":{"type":"BearerToken","token":"[REDACTED]"}}}}`,
+		},
+		{
+			name:     "it masks authorization pins",
+			input:    `{"directive":{"payload":{"authorization":{"type":"FOUR_DIGIT_PIN","value":"1234"},"value":42}}}`,
+			expected: `{"directive":{"payload":{"authorization":{"type":"FOUR_DIGIT_PIN","value":"[REDACTED]"},"value":42}}}`,
+		},
+		{
+			name:     "it masks tokens and secrets in arrays",
+			input:    `[{"access_token":"secret","refresh_token":"secret","expires_in":3600,"client_secret":"secret"}]`,
+			expected: `[{"access_token":"[REDACTED]","refresh_token":"[REDACTED]","expires_in":3600,"client_secret":"[REDACTED]"}]`,
+		},
+		{
+			name:     "it masks extra paths",
+			input:    `{"payload":{"secret":{"nested":true},"other":{"secret":"visible"}}}`,
+			expected: `{"payload":{"secret":"[REDACTED]","other":{"secret":"visible"}}}`,
+			extra:    []string{"payload.secret"},
+		},
+		{
+			name:     "it masks malformed json",
+			input:    `{"directive":{"payload":{"grantee":{"type":"BearerToken","token":"se\"cret"}}`,
+			expected: `{"directive":{"payload":{"grantee":{"type":"BearerToken","token":"[REDACTED]"}}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			redacted := redact.New(tc.extra...).JSON([]byte(tc.input))
+			if json.Valid([]byte(tc.expected)) {
+				assert.JSONEq(t, tc.expected, string(redacted))
+			} else {
+				assert.Equal(t, tc.expected, string(redacted))
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	r := redact.New()
+
+	assert.Equal(t, "Authorization: Bearer [REDACTED]", r.String("Authorization: Bearer Atza|secret"))
+	assert.Equal(t, "grant_type=authorization_code&code=[REDACTED]&client_secret=[REDACTED]", r.String("grant_type=authorization_code&code=abc&client_secret=xyz"))
+	assert.Equal(t, `{"scope":{"token":"[REDACTED]"}}`, r.String(` {"scope":{"token":"secret"}}`))
+	assert.Equal(t, "nothing to hide", r.String("nothing to hide"))
+}
+
+func TestField(t *testing.T) {
+	r := redact.New()
+
+	assert.Equal(t, redact.Mask, r.Field("password", "secret"))
+	assert.Equal(t, redact.Mask, r.Field("pin", 1234))
+	assert.Equal(t, `{"grant":{"code":"[REDACTED]"}}`, r.Field("body", []byte(`{"grant":{"code":"secret"}}`)))
+	assert.Equal(t, "could not refresh; refresh_token=[REDACTED]", r.Field("error", errors.New("could not refresh; refresh_token=secret")))
+
+	err := errors.New("harmless")
+	assert.Equal(t, err, r.Field("error", err))
+	assert.Equal(t, 42, r.Field("count", 42))
+}
@@ -0,0 +1,229 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome/redact"
+)
+
+// IgnoredFields are blanked in both responses before a replayed response gets compared with the recorded one,
+// because their values change with every response
+var IgnoredFields = []string{"messageId", "timeOfSample", "timestamp"}
+
+// Fixture is a directive received by a handler together with the response it sent
+type Fixture struct {
+	// Name of the fixture file without extension
+	Name string `json:"-"`
+
+	// Token replaces the redacted tokens of the directive when it's replayed, set it to replay against a handler
+	// verifying the scope. It's masked again in the replayed response before comparing it with the recorded one.
+	Token string `json:"-"`
+
+	Directive json.RawMessage `json:"directive"`
+	Response  json.RawMessage `json:"response"`
+}
+
+// LoadFixture reads a fixture file written by the Recorder
+func LoadFixture(file string) (Fixture, error) {
+	f := Fixture{Name: strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return f, fmt.Errorf("could not read fixture; %v", err)
+	}
+
+	if err = json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("could not unmarshal fixture %s; %v", f.Name, err)
+	}
+
+	if len(f.Directive) == 0 || len(f.Response) == 0 {
+		return f, fmt.Errorf("fixture %s is missing directive or response", f.Name)
+	}
+
+	return f, nil
+}
+
+// LoadFixtures reads all fixture files (*.json) of a directory ordered by name
+func LoadFixtures(dir string) ([]Fixture, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := make([]Fixture, 0, len(files))
+	for _, file := range files {
+		f, err := LoadFixture(file)
+		if err != nil {
+			return nil, err
+		}
+
+		fixtures = append(fixtures, f)
+	}
+
+	return fixtures, nil
+}
+
+// Replay sends the directive of the fixture to the handler and compares its response with the recorded one,
+// ignoring the IgnoredFields
+func (f Fixture) Replay(handler http.Handler) error {
+	directive := f.Directive
+	if f.Token != "" {
+		var err error
+		if directive, err = replaceTokens(directive, redact.Mask, f.Token); err != nil {
+			return fmt.Errorf("could not replace redacted tokens; %v", err)
+		}
+	}
+
+	body, err := json.Marshal(struct {
+		Directive json.RawMessage `json:"directive"`
+	}{directive})
+	if err != nil {
+		return fmt.Errorf("could not marshal request; %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+
+	if rec.Code != http.StatusOK {
+		return fmt.Errorf("handler responded with status %d; %s", rec.Code, strings.TrimSpace(rec.Body.String()))
+	}
+
+	response := rec.Body.Bytes()
+	if f.Token != "" {
+		if response, err = replaceTokens(response, f.Token, redact.Mask); err != nil {
+			return fmt.Errorf("could not mask tokens of response; %v", err)
+		}
+	}
+
+	current, err := Normalize(response)
+	if err != nil {
+		return fmt.Errorf("could not normalize response; %v", err)
+	}
+
+	expected, err := Normalize(f.Response)
+	if err != nil {
+		return fmt.Errorf("could not normalize recorded response; %v", err)
+	}
+
+	if !reflect.DeepEqual(current, expected) {
+		c, _ := json.MarshalIndent(current, "", "  ")
+		e, _ := json.MarshalIndent(expected, "", "  ")
+		return fmt.Errorf("response doesnt match fixture\nresponse:\n%s\nfixture:\n%s", c, e)
+	}
+
+	return nil
+}
+
+// Run replays every fixture of dir against the handler as a subtest
+func Run(t *testing.T, handler http.Handler, dir string) {
+	t.Helper()
+	RunWithToken(t, handler, dir, "")
+}
+
+// RunWithToken replays every fixture of dir against the handler as a subtest, the redacted tokens of the
+// directives are replaced by token (see Fixture.Token)
+func RunWithToken(t *testing.T, handler http.Handler, dir string, token string) {
+	t.Helper()
+
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatalf("could not load fixtures; %v", err)
+	}
+
+	if len(fixtures) == 0 {
+		t.Fatalf("no fixtures found in %s", dir)
+	}
+
+	for _, f := range fixtures {
+		f := f
+		f.Token = token
+		t.Run(f.Name, func(t *testing.T) {
+			if err := f.Replay(handler); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// replaceTokens replaces the values of all "token" fields which equal from
+func replaceTokens(document []byte, from, to string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(replaceToken(v, from, to))
+}
+
+func replaceToken(value interface{}, from, to string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if s, ok := child.(string); ok && key == "token" && s == from {
+				v[key] = to
+			} else {
+				v[key] = replaceToken(child, from, to)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = replaceToken(child, from, to)
+		}
+	}
+
+	return value
+}
+
+// Normalize decodes a JSON document and blanks the values of all IgnoredFields
+func Normalize(document []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return blank(v), nil
+}
+
+func blank(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if isIgnored(key) {
+				v[key] = ""
+			} else {
+				v[key] = blank(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = blank(child)
+		}
+	}
+
+	return value
+}
+
+func isIgnored(key string) bool {
+	for _, field := range IgnoredFields {
+		if field == key {
+			return true
+		}
+	}
+
+	return false
+}
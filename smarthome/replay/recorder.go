@@ -0,0 +1,131 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/redact"
+)
+
+// Now is used to change the current time for tests, defaults to time.Now()
+var Now = time.Now
+
+// unsafeChars are replaced in namespace and name of directives, which are sent by the client, to build file names
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// Recorder is a middleware recording every directive received by Handler and the response sent for it
+// as fixture file into Dir. Requests which could not be answered with a response (e.g. unauthorized or
+// malformed requests) are not recorded.
+type Recorder struct {
+	Handler http.Handler
+	Dir     string
+
+	// Redactor masks sensitive values like bearer tokens before a fixture gets written, defaults to redact.New()
+	Redactor *redact.Redactor
+
+	// OnError gets called if a fixture could not be written, the response is sent anyway
+	OnError func(err error)
+}
+
+// NewRecorder creates a recorder writing fixtures of handler into dir
+func NewRecorder(dir string, handler http.Handler) *Recorder {
+	return &Recorder{Handler: handler, Dir: dir, Redactor: redact.New()}
+}
+
+func (rec *Recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+	rec.Handler.ServeHTTP(rw, r)
+
+	if rw.status != http.StatusOK {
+		return
+	}
+
+	if err := rec.write(body, rw.body.Bytes()); err != nil && rec.OnError != nil {
+		rec.OnError(err)
+	}
+}
+
+func (rec *Recorder) write(request []byte, response []byte) error {
+	var req struct {
+		Directive json.RawMessage `json:"directive"`
+	}
+
+	if err := json.Unmarshal(request, &req); err != nil || len(req.Directive) == 0 {
+		return fmt.Errorf("could not record request without directive")
+	}
+
+	var header struct {
+		Header struct {
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+		} `json:"header"`
+	}
+
+	if err := json.Unmarshal(req.Directive, &header); err != nil {
+		return fmt.Errorf("could not record directive; %v", err)
+	}
+
+	if !json.Valid(response) {
+		return fmt.Errorf("could not record response of %s.%s, it's not valid json", header.Header.Namespace, header.Header.Name)
+	}
+
+	fixture, err := json.Marshal(Fixture{Directive: req.Directive, Response: response})
+	if err != nil {
+		return fmt.Errorf("could not marshal fixture; %v", err)
+	}
+
+	redactor := rec.Redactor
+	if redactor == nil {
+		redactor = redact.New()
+	}
+
+	var buffer bytes.Buffer
+	if err = json.Indent(&buffer, redactor.JSON(fixture), "", "  "); err != nil {
+		return fmt.Errorf("could not indent fixture; %v", err)
+	}
+
+	if err = os.MkdirAll(rec.Dir, 0755); err != nil {
+		return fmt.Errorf("could not create fixture directory; %v", err)
+	}
+
+	name := fmt.Sprintf("%s_%s.%s.json", Now().UTC().Format("20060102T150405.000000000"),
+		unsafeChars.ReplaceAllString(header.Header.Namespace, "_"), unsafeChars.ReplaceAllString(header.Header.Name, "_"))
+
+	dir := filepath.Clean(rec.Dir)
+	path := filepath.Join(dir, name)
+	if filepath.Dir(path) != dir {
+		return fmt.Errorf("could not record directive %s.%s, fixture would be written outside of %s", header.Header.Namespace, header.Header.Name, rec.Dir)
+	}
+
+	return ioutil.WriteFile(path, buffer.Bytes(), 0644)
+}
+
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
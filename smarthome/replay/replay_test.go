@@ -0,0 +1,196 @@
+package replay_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/directives/discovery"
+	"github.com/betom84/go-alexa/smarthome/identity"
+	"github.com/betom84/go-alexa/smarthome/replay"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createHandler() *smarthome.Handler {
+	device := &mocks.MockPowerDevice{}
+	device.On("State").Return(true, nil)
+
+	factory := &mocks.MockDeviceFactory{}
+	factory.On("NewDevice", "light", "L-1").Return(device, nil)
+
+	handler := smarthome.NewDefaultHandlerWithSource(nil, discovery.StaticEndpoints{})
+	handler.DeviceFactory = factory
+
+	return handler
+}
+
+func TestRun(t *testing.T) {
+	replay.Run(t, createHandler(), "testdata")
+}
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	replay.Now = func() time.Time { return time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC) }
+	defer func() { replay.Now = time.Now }()
+
+	request, err := ioutil.ReadFile("testdata/report_state.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	request = bytes.Replace(request, []byte("[REDACTED]"), []byte("access-token-from-skill"), -1)
+
+	var recordErr error
+	recorder := replay.NewRecorder(dir, createHandler())
+	recorder.OnError = func(err error) { recordErr = err }
+
+	rec := httptest.NewRecorder()
+	recorder.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(request)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, recordErr)
+
+	rec = httptest.NewRecorder()
+	recorder.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`useless`))))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.Equal(t, []string{filepath.Join(dir, "20210314T150926.000000000_Alexa.ReportState.json")}, files)
+
+	fixture, err := ioutil.ReadFile(files[0])
+	assert.NoError(t, err)
+	assert.NotContains(t, string(fixture), "access-token-from-skill", "bearer token must be redacted")
+
+	replay.Run(t, createHandler(), dir)
+}
+
+func TestRecorderPathTraversal(t *testing.T) {
+	root, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	replay.Now = func() time.Time { return time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC) }
+	defer func() { replay.Now = time.Now }()
+
+	dir := filepath.Join(root, "a", "b")
+
+	var recordErr error
+	recorder := replay.NewRecorder(dir, createHandler())
+	recorder.OnError = func(err error) { recordErr = err }
+
+	request := `{"directive":{"header":{"namespace":"../../escaped","name":"x/../..\\y","messageId":"1","payloadVersion":"3"},"payload":{}}}`
+
+	rec := httptest.NewRecorder()
+	recorder.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(request))))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, recordErr)
+
+	var files []string
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	})
+
+	assert.Equal(t, []string{filepath.Join(dir, "20210314T150926.000000000_.._.._escaped.x_.._.._y.json")}, files)
+}
+
+func TestFixtureReplay(t *testing.T) {
+	common.ConstMessageID = "any-const-message-id-for-test"
+
+	tt := []struct {
+		name        string
+		response    string
+		handler     http.Handler
+		expectError string
+	}{
+		{
+			name:     "it ignores message id and timestamps",
+			response: `{"event":{"header":{"messageId":"recorded"},"payload":{"timestamp":"2019-01-01T00:00:00Z"}},"context":{"properties":[{"timeOfSample":"2019-01-01T00:00:00Z","value":"ON"}]}}`,
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"context":{"properties":[{"value":"ON","timeOfSample":"2021-01-01T00:00:00Z"}]},"event":{"header":{"messageId":"replayed"},"payload":{"timestamp":"2021-01-01T00:00:00Z"}}}`))
+			}),
+		},
+		{
+			name:     "it detects changed responses",
+			response: `{"context":{"properties":[{"value":"ON"}]}}`,
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"context":{"properties":[{"value":"OFF"}]}}`))
+			}),
+			expectError: "response doesnt match fixture",
+		},
+		{
+			name:     "it fails on unexpected status",
+			response: `{}`,
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "nope", http.StatusUnauthorized)
+			}),
+			expectError: "handler responded with status 401; nope",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			f := replay.Fixture{Directive: []byte(`{"header":{"namespace":"Alexa"}}`), Response: []byte(tc.response)}
+
+			err := f.Replay(tc.handler)
+			if tc.expectError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReplayWithToken(t *testing.T) {
+	handler := createHandler()
+	handler.ScopeVerifier = identity.VerifierFunc(func(scope common.Scope) (*common.Identity, error) {
+		if scope.Token != "token-for-test" {
+			return nil, identity.ErrInvalidToken
+		}
+
+		return &common.Identity{Email: "somebody@mail.com"}, nil
+	})
+
+	fixtures, err := replay.LoadFixtures("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fixtures[0].Replay(handler)
+	if assert.Error(t, err, "redacted token should be rejected by the handler") {
+		assert.Contains(t, err.Error(), "INVALID_AUTHORIZATION_CREDENTIAL")
+	}
+
+	fixtures[0].Token = "token-for-test"
+	assert.NoError(t, fixtures[0].Replay(handler))
+
+	replay.RunWithToken(t, handler, "testdata", "token-for-test")
+}
+
+func TestLoadFixtures(t *testing.T) {
+	_, err := replay.LoadFixture("testdata/missing.json")
+	assert.Error(t, err)
+
+	fixtures, err := replay.LoadFixtures("testdata")
+	assert.NoError(t, err)
+	assert.Len(t, fixtures, 1)
+	assert.Equal(t, "report_state", fixtures[0].Name)
+}
@@ -0,0 +1,109 @@
+package smarthome_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/identity"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandlerScopeVerification(t *testing.T) {
+	common.ConstMessageID = "any-const-message-id-for-test"
+
+	somebody := &common.Identity{Email: "somebody@mail.com"}
+	verifier := identity.VerifierFunc(func(scope common.Scope) (*common.Identity, error) {
+		switch scope.Token {
+		case "access-token-from-skill":
+			return nil, identity.ErrInvalidToken
+		case "valid-token":
+			return somebody, nil
+		case "wrapped-invalid-token":
+			return nil, fmt.Errorf("profile lookup failed; %w", identity.ErrInvalidToken)
+		default:
+			return nil, fmt.Errorf("identity provider unavailable")
+		}
+	})
+
+	tt := []struct {
+		name             string
+		request          []byte
+		expectedResponse []byte
+		setup            func(*smarthome.Handler, *testing.T)
+	}{
+		{
+			name:             "it rejects directives with invalid scope token",
+			request:          readFile(t, "testdata/process_directive_request.json"),
+			expectedResponse: readFile(t, "testdata/invalid_authorization_credential_response.json"),
+		},
+		{
+			name:             "it rejects directives with invalid scope token wrapped by the verifier",
+			request:          bytes.Replace(readFile(t, "testdata/process_directive_request.json"), []byte("access-token-from-skill"), []byte("wrapped-invalid-token"), 1),
+			expectedResponse: bytes.Replace(readFile(t, "testdata/invalid_authorization_credential_response.json"), []byte("access-token-from-skill"), []byte("wrapped-invalid-token"), 1),
+		},
+		{
+			name:             "it rejects directives without scope",
+			request:          []byte(`{"directive":{"header":{"namespace":"Alexa.Discovery","name":"Discover"},"payload":{}}}`),
+			expectedResponse: []byte(`{"event":{"header":{"namespace":"Alexa","name":"ErrorResponse","messageId":"any-const-message-id-for-test","payloadVersion":"3"},"payload":{"type":"INVALID_AUTHORIZATION_CREDENTIAL","message":"directive does not contain a scope"}}}`),
+		},
+		{
+			name:             "it responds with internal error when verification fails",
+			request:          []byte(`{"directive":{"header":{"namespace":"Alexa.Discovery","name":"Discover"},"payload":{"scope":{"type":"BearerToken","token":"other-token"}}}}`),
+			expectedResponse: []byte(`{"event":{"header":{"namespace":"Alexa","name":"ErrorResponse","messageId":"any-const-message-id-for-test","payloadVersion":"3"},"payload":{"type":"INTERNAL_ERROR","message":"identity provider unavailable"}}}`),
+		},
+		{
+			name:             "it skips verification of authorization directives",
+			request:          []byte(`{"directive":{"header":{"namespace":"Alexa.Authorization","name":"AcceptGrant"},"payload":{}}}`),
+			expectedResponse: []byte(`{"event":{"header":null}}`),
+			setup: func(h *smarthome.Handler, t *testing.T) {
+				p := createMockDirectiveProcessor(true, nil)
+				h.AddDirectiveProcessor(p)
+			},
+		},
+		{
+			name:             "it passes verified identity to processor and device factory",
+			request:          bytes.Replace(readFile(t, "testdata/process_directive_request.json"), []byte("access-token-from-skill"), []byte("valid-token"), 1),
+			expectedResponse: []byte(`{"event":{"header":null}}`),
+			setup: func(h *smarthome.Handler, t *testing.T) {
+				f := &mocks.MockUserDeviceFactory{}
+				f.On("NewUserDevice", somebody, "testing", "ABC-123").Return("device of somebody", nil)
+				h.DeviceFactory = f
+
+				p := &mocks.MockDirectiveProcessor{}
+				p.On("IsCapable", mock.Anything).Return(true)
+				p.On("Process", mock.MatchedBy(func(dir *common.Directive) bool {
+					return dir.Identity == somebody && dir.Endpoint.Scope.Identity == somebody
+				}), "device of somebody").Return(&common.Response{}, nil)
+				h.AddDirectiveProcessor(p)
+
+				t.Cleanup(func() {
+					f.AssertExpectations(t)
+					p.AssertExpectations(t)
+				})
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := smarthome.Handler{ScopeVerifier: verifier}
+			if tc.setup != nil {
+				tc.setup(&handler, t)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(tc.request)))
+
+			body, _ := ioutil.ReadAll(rec.Result().Body)
+			assert.JSONEq(t, string(tc.expectedResponse), string(body))
+		})
+	}
+}
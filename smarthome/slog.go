@@ -0,0 +1,53 @@
+//go:build go1.21
+// +build go1.21
+
+package smarthome
+
+import (
+	"context"
+	"log/slog"
+)
+
+// FromSlog adapts a log/slog logger to a StructuredLogger, levels are mapped to the slog levels. Trace is
+// logged below slog.LevelDebug and Fatal above slog.LevelError.
+func FromSlog(logger *slog.Logger) StructuredLogger {
+	return slogLogger{logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) Log(level int, message string, fields ...Field) {
+	l.logger.Log(context.Background(), slogLevel(level), message, slogArgs(fields)...)
+}
+
+func (l slogLogger) With(fields ...Field) StructuredLogger {
+	return slogLogger{l.logger.With(slogArgs(fields)...)}
+}
+
+func slogLevel(level int) slog.Level {
+	switch level {
+	case Trace:
+		return slog.LevelDebug - 4
+	case Debug:
+		return slog.LevelDebug
+	case Info:
+		return slog.LevelInfo
+	case Warning:
+		return slog.LevelWarn
+	case Error:
+		return slog.LevelError
+	default:
+		return slog.LevelError + 4
+	}
+}
+
+func slogArgs(fields []Field) []interface{} {
+	args := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		args = append(args, slog.Any(field.Key, field.Value))
+	}
+
+	return args
+}
@@ -0,0 +1,33 @@
+//go:build go1.21
+// +build go1.21
+
+package smarthome_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromSlog(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := smarthome.FromSlog(slog.New(slog.NewJSONHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	logger.With(smarthome.Any("messageId", "abc-123")).Log(smarthome.Warning, "Something happened", smarthome.Any("endpointId", "appliance-001"))
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buffer.Bytes(), &entry))
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "Something happened", entry["msg"])
+	assert.Equal(t, "abc-123", entry["messageId"])
+	assert.Equal(t, "appliance-001", entry["endpointId"])
+
+	buffer.Reset()
+	logger.Log(smarthome.Trace, "Filtered by level")
+	assert.Empty(t, buffer.String())
+}
@@ -0,0 +1,185 @@
+package smarthometest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+)
+
+// Serve sends the directive to the handler and decodes the response, the test fails if the handler
+// didn't respond with a directive response
+func Serve(t testing.TB, handler http.Handler, b *DirectiveBuilder) *common.Response {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, b.Request())
+
+	body, _ := ioutil.ReadAll(rec.Result().Body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("handler responded with status %d; %s", rec.Code, body)
+	}
+
+	response := new(common.Response)
+	if err := json.Unmarshal(body, response); err != nil {
+		t.Fatalf("could not unmarshal response; %v", err)
+	}
+
+	return response
+}
+
+// AssertResponse checks namespace and name of the response header
+func AssertResponse(t testing.TB, response *common.Response, namespace string, name string) bool {
+	t.Helper()
+
+	if response == nil || response.Event.Header == nil {
+		t.Errorf("expected %s.%s response, but got none", namespace, name)
+		return false
+	}
+
+	header := response.Event.Header
+	if header.Namespace != namespace || header.Name != name {
+		t.Errorf("expected %s.%s response, but got %s.%s; %s", namespace, name, header.Namespace, header.Name, marshal(response.Event.Payload))
+		return false
+	}
+
+	return true
+}
+
+// AssertErrorResponse checks that the response is an Alexa.ErrorResponse of the given type, like "NO_SUCH_ENDPOINT"
+func AssertErrorResponse(t testing.TB, response *common.Response, errorType string) bool {
+	t.Helper()
+
+	if !AssertResponse(t, response, "Alexa", "ErrorResponse") {
+		return false
+	}
+
+	var payload struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(marshal(response.Event.Payload), &payload)
+
+	if payload.Type != errorType {
+		t.Errorf("expected error of type %s, but got %s; %s", errorType, payload.Type, payload.Message)
+		return false
+	}
+
+	return true
+}
+
+// AssertEndpoint checks the endpoint id of the response
+func AssertEndpoint(t testing.TB, response *common.Response, endpointID string) bool {
+	t.Helper()
+
+	if response == nil || response.Event.Endpoint == nil {
+		t.Errorf("expected response for endpoint %s, but response has no endpoint", endpointID)
+		return false
+	}
+
+	if response.Event.Endpoint.EndpointID != endpointID {
+		t.Errorf("expected response for endpoint %s, but got %s", endpointID, response.Event.Endpoint.EndpointID)
+		return false
+	}
+
+	return true
+}
+
+// AssertProperty checks that the context of the response contains the property with the given value. Values
+// are compared by their JSON representation, e.g. "ON" for powerState or map[string]interface{}{"value": "OK"}
+// for connectivity.
+func AssertProperty(t testing.TB, response *common.Response, namespace string, name string, value interface{}) bool {
+	t.Helper()
+
+	if response == nil || response.Context == nil {
+		t.Errorf("expected property %s.%s, but response has no context", namespace, name)
+		return false
+	}
+
+	for _, p := range response.Context.Properties {
+		if p.Namespace != namespace || p.Name != name {
+			continue
+		}
+
+		if !jsonEqual(p.Value, value) {
+			t.Errorf("expected property %s.%s to be %s, but got %s", namespace, name, marshal(value), marshal(p.Value))
+			return false
+		}
+
+		return true
+	}
+
+	t.Errorf("expected property %s.%s, but got %s", namespace, name, marshal(response.Context.Properties))
+	return false
+}
+
+// AssertNoProperty checks that the context of the response doesn't contain the property
+func AssertNoProperty(t testing.TB, response *common.Response, namespace string, name string) bool {
+	t.Helper()
+
+	if response == nil || response.Context == nil {
+		return true
+	}
+
+	for _, p := range response.Context.Properties {
+		if p.Namespace == namespace && p.Name == name {
+			t.Errorf("expected no property %s.%s, but got %s", namespace, name, marshal(p.Value))
+			return false
+		}
+	}
+
+	return true
+}
+
+// AssertDiscovered checks that the discover response contains exactly the given endpoints
+func AssertDiscovered(t testing.TB, response *common.Response, endpointIDs ...string) bool {
+	t.Helper()
+
+	if !AssertResponse(t, response, "Alexa.Discovery", "Discover.Response") {
+		return false
+	}
+
+	var payload struct {
+		Endpoints []struct {
+			EndpointID string `json:"endpointId"`
+		} `json:"endpoints"`
+	}
+	_ = json.Unmarshal(marshal(response.Event.Payload), &payload)
+
+	discovered := []string{}
+	for _, ep := range payload.Endpoints {
+		discovered = append(discovered, ep.EndpointID)
+	}
+
+	if len(endpointIDs) == 0 {
+		endpointIDs = []string{}
+	}
+
+	if !reflect.DeepEqual(discovered, endpointIDs) {
+		t.Errorf("expected endpoints %v to be discovered, but got %v", endpointIDs, discovered)
+		return false
+	}
+
+	return true
+}
+
+func jsonEqual(a interface{}, b interface{}) bool {
+	var x, y interface{}
+	_ = json.Unmarshal(marshal(a), &x)
+	_ = json.Unmarshal(marshal(b), &y)
+
+	return reflect.DeepEqual(x, y)
+}
+
+func marshal(v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return []byte(err.Error())
+	}
+
+	return data
+}
@@ -0,0 +1,180 @@
+package smarthometest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+)
+
+// Health is embedded by the in-memory devices to satisfy capabilities.HealthConscious, devices are connected by default
+type Health struct {
+	mutex        sync.Mutex
+	disconnected bool
+}
+
+// IsConnected satisfies capabilities.HealthConscious
+func (h *Health) IsConnected() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return !h.disconnected
+}
+
+// SetConnected changes the connectivity reported by the device
+func (h *Health) SetConnected(connected bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.disconnected = !connected
+}
+
+// PowerDevice is an in-memory device satisfying capabilities.PowerDevice
+type PowerDevice struct {
+	Health
+
+	mutex sync.Mutex
+	on    bool
+	err   error
+	calls int
+}
+
+// NewPowerDevice creates a power device with the given initial state
+func NewPowerDevice(on bool) *PowerDevice {
+	return &PowerDevice{on: on}
+}
+
+// SetState satisfies capabilities.PowerDevice
+func (d *PowerDevice) SetState(on bool) (bool, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.calls++
+	if d.err != nil {
+		return d.on, d.err
+	}
+
+	d.on = on
+	return d.on, nil
+}
+
+// State satisfies capabilities.PowerDevice
+func (d *PowerDevice) State() (bool, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.on, d.err
+}
+
+// IsOn returns the current state of the device
+func (d *PowerDevice) IsOn() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.on
+}
+
+// SetStateCalls returns how often the state was set
+func (d *PowerDevice) SetStateCalls() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.calls
+}
+
+// FailWith lets State and SetState return the given error, nil restores normal operation
+func (d *PowerDevice) FailWith(err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.err = err
+}
+
+// TemperatureSensor is an in-memory device satisfying capabilities.TemperatureSensor
+type TemperatureSensor struct {
+	Health
+
+	mutex       sync.Mutex
+	temperature float32
+}
+
+// NewTemperatureSensor creates a sensor measuring the given temperature in celsius
+func NewTemperatureSensor(temperature float32) *TemperatureSensor {
+	return &TemperatureSensor{temperature: temperature}
+}
+
+// Temperature satisfies capabilities.TemperatureSensor
+func (d *TemperatureSensor) Temperature() float32 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.temperature
+}
+
+// SetTemperature changes the measured temperature in celsius
+func (d *TemperatureSensor) SetTemperature(temperature float32) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.temperature = temperature
+}
+
+// DeviceFactory is a smarthome.DeviceFactory creating the devices added to it. It's also a discovery.EndpointSource
+// providing the endpoints added along with their devices.
+type DeviceFactory struct {
+	mutex     sync.Mutex
+	devices   map[string]interface{}
+	endpoints []discoverable.Endpoint
+}
+
+// NewDeviceFactory creates an empty device factory
+func NewDeviceFactory() *DeviceFactory {
+	return &DeviceFactory{devices: make(map[string]interface{})}
+}
+
+// Add the device to be created for the given endpoint type and id
+func (f *DeviceFactory) Add(epType string, id string, device interface{}) *DeviceFactory {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.devices[f.key(epType, id)] = device
+	return f
+}
+
+// AddEndpoint adds the device to be created for the cookie of the endpoint and provides the endpoint for discovery
+func (f *DeviceFactory) AddEndpoint(endpoint discoverable.Endpoint, device interface{}) *DeviceFactory {
+	f.Add(endpoint.Cookie.Type, endpoint.Cookie.ID, device)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.endpoints = append(f.endpoints, endpoint)
+	return f
+}
+
+// NewDevice satisfies smarthome.DeviceFactory, it returns a device not found error for unknown devices
+func (f *DeviceFactory) NewDevice(epType string, id string) (interface{}, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	device, ok := f.devices[f.key(epType, id)]
+	if !ok {
+		return nil, smarthome.NewDeviceNotFoundError(fmt.Sprintf("device %s/%s does not exist", epType, id))
+	}
+
+	return device, nil
+}
+
+// Endpoints satisfies discovery.EndpointSource, it returns all endpoints added regardless of the scope
+func (f *DeviceFactory) Endpoints(scope common.Scope) ([]discoverable.Endpoint, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]discoverable.Endpoint{}, f.endpoints...), nil
+}
+
+func (f *DeviceFactory) key(epType string, id string) string {
+	return epType + "/" + id
+}
@@ -0,0 +1,176 @@
+// Package smarthometest provides utilities to test directive processors and handlers: builders for the
+// directives sent by alexa, in-memory devices, fake login with amazon and event gateway servers and
+// assertions for responses.
+package smarthometest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+)
+
+// Defaults of the directives created by the builders
+const (
+	DefaultMessageID        = "message-id-for-test"
+	DefaultCorrelationToken = "correlation-token-for-test"
+	DefaultToken            = "access-token-for-test"
+)
+
+// DirectiveBuilder builds directives like they are sent by alexa, start with one of the constructors
+// like TurnOn or Discover and chain the With... methods to change the defaults
+type DirectiveBuilder struct {
+	dir common.Directive
+}
+
+// NewDirective starts a directive with the given namespace and name, without endpoint and payload
+func NewDirective(namespace string, name string) *DirectiveBuilder {
+	return &DirectiveBuilder{dir: common.Directive{
+		Header: &common.Header{
+			Namespace:        namespace,
+			Name:             name,
+			MessageID:        DefaultMessageID,
+			CorrelationToken: DefaultCorrelationToken,
+			PayloadVersion:   "3",
+		},
+		Payload: map[string]interface{}{},
+	}}
+}
+
+// NewEndpointDirective starts a directive with the given namespace and name, targeting the endpoint
+func NewEndpointDirective(namespace string, name string, endpointID string) *DirectiveBuilder {
+	b := NewDirective(namespace, name)
+	b.dir.Endpoint = &common.Endpoint{
+		Scope:      common.Scope{Type: "BearerToken", Token: DefaultToken},
+		EndpointID: endpointID,
+	}
+
+	return b
+}
+
+// AcceptGrant starts an Alexa.Authorization.AcceptGrant directive for the grant code and grantee token
+func AcceptGrant(code string, granteeToken string) *DirectiveBuilder {
+	b := NewDirective("Alexa.Authorization", "AcceptGrant")
+	b.dir.Header.CorrelationToken = ""
+	b.dir.Payload["grant"] = map[string]interface{}{"type": "OAuth2.AuthorizationCode", "code": code}
+	b.dir.Payload["grantee"] = map[string]interface{}{"type": "BearerToken", "token": granteeToken}
+
+	return b
+}
+
+// Discover starts an Alexa.Discovery.Discover directive
+func Discover() *DirectiveBuilder {
+	b := NewDirective("Alexa.Discovery", "Discover")
+	b.dir.Header.CorrelationToken = ""
+	b.dir.Payload["scope"] = map[string]interface{}{"type": "BearerToken", "token": DefaultToken}
+
+	return b
+}
+
+// TurnOn starts an Alexa.PowerController.TurnOn directive for the endpoint
+func TurnOn(endpointID string) *DirectiveBuilder {
+	return NewEndpointDirective("Alexa.PowerController", "TurnOn", endpointID)
+}
+
+// TurnOff starts an Alexa.PowerController.TurnOff directive for the endpoint
+func TurnOff(endpointID string) *DirectiveBuilder {
+	return NewEndpointDirective("Alexa.PowerController", "TurnOff", endpointID)
+}
+
+// ReportState starts an Alexa.ReportState directive for the endpoint
+func ReportState(endpointID string) *DirectiveBuilder {
+	return NewEndpointDirective("Alexa", "ReportState", endpointID)
+}
+
+// WithMessageID sets the message id of the header
+func (b *DirectiveBuilder) WithMessageID(messageID string) *DirectiveBuilder {
+	b.dir.Header.MessageID = messageID
+	return b
+}
+
+// WithCorrelationToken sets the correlation token of the header
+func (b *DirectiveBuilder) WithCorrelationToken(token string) *DirectiveBuilder {
+	b.dir.Header.CorrelationToken = token
+	return b
+}
+
+// WithPayloadVersion sets the payload version of the header
+func (b *DirectiveBuilder) WithPayloadVersion(version string) *DirectiveBuilder {
+	b.dir.Header.PayloadVersion = version
+	return b
+}
+
+// WithToken sets the bearer token of the scope, which is part of the endpoint or the payload for discover directives
+func (b *DirectiveBuilder) WithToken(token string) *DirectiveBuilder {
+	if b.dir.Endpoint != nil {
+		b.dir.Endpoint.Scope.Token = token
+	} else {
+		b.dir.Payload["scope"] = map[string]interface{}{"type": "BearerToken", "token": token}
+	}
+
+	return b
+}
+
+// WithCookie sets the cookie of the endpoint, which is used to create the device by the DeviceFactory
+func (b *DirectiveBuilder) WithCookie(epType string, id string, name string) *DirectiveBuilder {
+	if b.dir.Endpoint == nil {
+		b.dir.Endpoint = &common.Endpoint{Scope: common.Scope{Type: "BearerToken", Token: DefaultToken}}
+	}
+
+	b.dir.Endpoint.Cookie = common.Cookie{Type: epType, ID: id, Name: name}
+	return b
+}
+
+// WithIdentity sets the identity of the directive, as if the scope got verified by the handler
+func (b *DirectiveBuilder) WithIdentity(identity *common.Identity) *DirectiveBuilder {
+	b.dir.Identity = identity
+	return b
+}
+
+// WithPayload sets a field of the payload
+func (b *DirectiveBuilder) WithPayload(key string, value interface{}) *DirectiveBuilder {
+	b.dir.Payload[key] = value
+	return b
+}
+
+// Build returns a copy of the directive, the builder can be changed further afterwards
+func (b *DirectiveBuilder) Build() *common.Directive {
+	dir := b.dir
+
+	header := *b.dir.Header
+	dir.Header = &header
+
+	if b.dir.Endpoint != nil {
+		endpoint := *b.dir.Endpoint
+		dir.Endpoint = &endpoint
+	}
+
+	dir.Payload = make(map[string]interface{}, len(b.dir.Payload))
+	for k, v := range b.dir.Payload {
+		dir.Payload[k] = v
+	}
+
+	return &dir
+}
+
+// JSON returns the request body alexa sends for the directive
+func (b *DirectiveBuilder) JSON() []byte {
+	body, err := json.Marshal(struct {
+		Directive *common.Directive `json:"directive"`
+	}{b.Build()})
+	if err != nil {
+		panic(err)
+	}
+
+	return body
+}
+
+// Request returns a request to send the directive to a handler
+func (b *DirectiveBuilder) Request() *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b.JSON()))
+	request.Header.Set("Content-Type", "application/json")
+
+	return request
+}
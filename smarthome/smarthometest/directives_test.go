@@ -0,0 +1,96 @@
+package smarthometest_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/smarthometest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectiveBuilders(t *testing.T) {
+	tt := []struct {
+		name     string
+		builder  *smarthometest.DirectiveBuilder
+		expected string
+	}{
+		{
+			name:     "accept grant",
+			builder:  smarthometest.AcceptGrant("code", "grantee-token"),
+			expected: `{"directive":{"header":{"namespace":"Alexa.Authorization","name":"AcceptGrant","messageId":"message-id-for-test","payloadVersion":"3"},"payload":{"grant":{"type":"OAuth2.AuthorizationCode","code":"code"},"grantee":{"type":"BearerToken","token":"grantee-token"}}}}`,
+		},
+		{
+			name:     "discover",
+			builder:  smarthometest.Discover().WithToken("token").WithMessageID("abc"),
+			expected: `{"directive":{"header":{"namespace":"Alexa.Discovery","name":"Discover","messageId":"abc","payloadVersion":"3"},"payload":{"scope":{"type":"BearerToken","token":"token"}}}}`,
+		},
+		{
+			name:     "turn on",
+			builder:  smarthometest.TurnOn("lamp-1").WithCookie("light", "L1", "Lamp"),
+			expected: `{"directive":{"header":{"namespace":"Alexa.PowerController","name":"TurnOn","messageId":"message-id-for-test","correlationToken":"correlation-token-for-test","payloadVersion":"3"},"endpoint":{"scope":{"type":"BearerToken","token":"access-token-for-test"},"endpointId":"lamp-1","cookie":{"id":"L1","type":"light","name":"Lamp"}}}}`,
+		},
+		{
+			name:     "turn off",
+			builder:  smarthometest.TurnOff("lamp-1").WithCorrelationToken("ct").WithToken("token"),
+			expected: `{"directive":{"header":{"namespace":"Alexa.PowerController","name":"TurnOff","messageId":"message-id-for-test","correlationToken":"ct","payloadVersion":"3"},"endpoint":{"scope":{"type":"BearerToken","token":"token"},"endpointId":"lamp-1","cookie":{"id":"","type":"","name":""}}}}`,
+		},
+		{
+			name:     "report state",
+			builder:  smarthometest.ReportState("lamp-1").WithPayloadVersion("3.1").WithPayload("extra", 1),
+			expected: `{"directive":{"header":{"namespace":"Alexa","name":"ReportState","messageId":"message-id-for-test","correlationToken":"correlation-token-for-test","payloadVersion":"3.1"},"endpoint":{"scope":{"type":"BearerToken","token":"access-token-for-test"},"endpointId":"lamp-1","cookie":{"id":"","type":"","name":""}},"payload":{"extra":1}}}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.JSONEq(t, tc.expected, string(tc.builder.JSON()))
+		})
+	}
+}
+
+func TestBuildReturnsCopy(t *testing.T) {
+	b := smarthometest.TurnOn("lamp-1").WithIdentity(&common.Identity{Email: "jane@example.com"})
+
+	dir := b.Build()
+	b.WithToken("other").WithPayload("key", "value")
+
+	assert.Equal(t, smarthometest.DefaultToken, dir.Endpoint.Scope.Token)
+	assert.Empty(t, dir.Payload)
+	assert.Equal(t, "jane@example.com", dir.Identity.Email)
+}
+
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	resp := new(common.Response)
+	resp.Event.Header = common.NewHeader("StateReport", "Alexa")
+	resp.Context = common.NewContext()
+	resp.Context.AddPowerStateProperty(false, time.Now())
+
+	rec := &recordingT{TB: t}
+
+	assert.True(t, smarthometest.AssertResponse(rec, resp, "Alexa", "StateReport"))
+	assert.True(t, smarthometest.AssertProperty(rec, resp, "Alexa.PowerController", "powerState", "OFF"))
+	assert.Empty(t, rec.errors)
+
+	assert.False(t, smarthometest.AssertResponse(rec, resp, "Alexa", "Response"))
+	assert.False(t, smarthometest.AssertErrorResponse(rec, resp, "NO_SUCH_ENDPOINT"))
+	assert.False(t, smarthometest.AssertProperty(rec, resp, "Alexa.PowerController", "powerState", "ON"))
+	assert.False(t, smarthometest.AssertProperty(rec, resp, "Alexa.EndpointHealth", "connectivity", "OK"))
+	assert.False(t, smarthometest.AssertNoProperty(rec, resp, "Alexa.PowerController", "powerState"))
+	assert.False(t, smarthometest.AssertEndpoint(rec, resp, "lamp-1"))
+	assert.False(t, smarthometest.AssertDiscovered(rec, resp))
+	assert.Len(t, rec.errors, 7)
+}
@@ -0,0 +1,96 @@
+package smarthometest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/gateway"
+)
+
+// Event received by the fake event gateway
+type Event struct {
+	// Token the event got authorized with
+	Token string
+
+	// Body of the event as sent
+	Body json.RawMessage
+
+	// Response decoded from the body
+	Response *common.Response
+}
+
+// EventGateway is a fake alexa event gateway recording the events sent to it
+type EventGateway struct {
+	*httptest.Server
+
+	mutex  sync.Mutex
+	events []Event
+	status int
+}
+
+// NewEventGateway starts a fake event gateway accepting all events, close it when done
+func NewEventGateway() *EventGateway {
+	g := &EventGateway{status: http.StatusAccepted}
+	g.Server = httptest.NewServer(http.HandlerFunc(g.serveEvent))
+
+	return g
+}
+
+// Gateway creates a client sending events to this gateway on behalf of the users of the token store
+func (g *EventGateway) Gateway(tokens gateway.TokenStore) *gateway.Gateway {
+	return &gateway.Gateway{URL: g.URL, Tokens: tokens, Client: g.Client()}
+}
+
+// RespondWith changes the status code the gateway responds with, events are recorded regardless of the status
+func (g *EventGateway) RespondWith(status int) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.status = status
+}
+
+// Events returns the events received so far
+func (g *EventGateway) Events() []Event {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return append([]Event{}, g.events...)
+}
+
+// Reset discards the events received so far
+func (g *EventGateway) Reset() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.events = nil
+}
+
+func (g *EventGateway) serveEvent(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	response := new(common.Response)
+	if err = json.Unmarshal(body, response); err != nil {
+		http.Error(w, "INVALID_REQUEST_EXCEPTION", http.StatusBadRequest)
+		return
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.events = append(g.events, Event{
+		Token:    strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "),
+		Body:     body,
+		Response: response,
+	})
+
+	w.WriteHeader(g.status)
+}
@@ -0,0 +1,192 @@
+package smarthometest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/directives/authorization"
+	"github.com/betom84/go-alexa/smarthome/identity"
+)
+
+// Paths served by the fake login with amazon server
+const (
+	LWATokenPath   = "/auth/o2/token"
+	LWAProfilePath = "/user/profile"
+)
+
+// LWA is a fake login with amazon server. It issues tokens for the grant codes created by Grant, refreshes
+// them and answers profile requests for grantee and access tokens.
+type LWA struct {
+	*httptest.Server
+
+	ClientID     string
+	ClientSecret string
+
+	mutex         sync.Mutex
+	seq           int
+	codes         map[string]common.Identity
+	tokens        map[string]common.Identity
+	refreshTokens map[string]common.Identity
+}
+
+// NewLWA starts a fake login with amazon server accepting the given client credentials, any credentials are accepted
+// if the client id is empty. Close it when done.
+func NewLWA(clientID string, clientSecret string) *LWA {
+	l := newLWA(clientID, clientSecret)
+	l.Start()
+
+	return l
+}
+
+// NewLWAAt starts a fake login with amazon server listening on the given address, like "127.0.0.1:8085"
+func NewLWAAt(addr string, clientID string, clientSecret string) (*LWA, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen on %s; %v", addr, err)
+	}
+
+	l := newLWA(clientID, clientSecret)
+	l.Listener.Close()
+	l.Listener = listener
+	l.Start()
+
+	return l, nil
+}
+
+func newLWA(clientID string, clientSecret string) *LWA {
+	l := &LWA{
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		codes:         make(map[string]common.Identity),
+		tokens:        make(map[string]common.Identity),
+		refreshTokens: make(map[string]common.Identity),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(LWATokenPath, l.serveToken)
+	mux.HandleFunc(LWAProfilePath, l.serveProfile)
+	l.Server = httptest.NewUnstartedServer(mux)
+
+	return l
+}
+
+// Grant creates a grant code and a grantee token for the user, like alexa does after the user linked the account.
+// Use them to build the AcceptGrant directive.
+func (l *LWA) Grant(user common.Identity) (code string, granteeToken string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.seq++
+	code = fmt.Sprintf("grant-code-%d", l.seq)
+	granteeToken = fmt.Sprintf("grantee-token-%d", l.seq)
+
+	l.codes[code] = user
+	l.tokens[granteeToken] = user
+
+	return
+}
+
+// Provider returns the identity provider requesting this server
+func (l *LWA) Provider() authorization.LWA {
+	return authorization.LWA{TokenURL: l.TokenURL(), ProfileURL: l.ProfileURL(), Client: l.Client()}
+}
+
+// TokenURL of this server, e.g. to be used as gateway.RefreshTokenURL
+func (l *LWA) TokenURL() string {
+	return l.URL + LWATokenPath
+}
+
+// ProfileURL of this server, e.g. to be used as identity.ProfileURL
+func (l *LWA) ProfileURL() string {
+	return l.URL + LWAProfilePath
+}
+
+// Verify satisfies identity.Verifier, it resolves the identity of the grantee and access tokens issued by this server
+func (l *LWA) Verify(scope common.Scope) (*common.Identity, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	user, ok := l.tokens[scope.Token]
+	if !ok {
+		return nil, identity.ErrInvalidToken
+	}
+
+	return &user, nil
+}
+
+func (l *LWA) serveToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if l.ClientID != "" && (r.PostFormValue("client_id") != l.ClientID || r.PostFormValue("client_secret") != l.ClientSecret) {
+		l.writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "invalid_client"})
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var user common.Identity
+	var ok bool
+
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		code := r.PostFormValue("code")
+		if user, ok = l.codes[code]; ok {
+			delete(l.codes, code)
+		}
+	case "refresh_token":
+		user, ok = l.refreshTokens[r.PostFormValue("refresh_token")]
+	default:
+		l.writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "unsupported_grant_type"})
+		return
+	}
+
+	if !ok {
+		l.writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid_grant"})
+		return
+	}
+
+	l.seq++
+	accessToken := fmt.Sprintf("access-token-%d", l.seq)
+	refreshToken := fmt.Sprintf("refresh-token-%d", l.seq)
+
+	l.tokens[accessToken] = user
+	l.refreshTokens[refreshToken] = user
+
+	l.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+		"token_type":    "bearer",
+		"expires_in":    3600,
+	})
+}
+
+func (l *LWA) serveProfile(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	l.mutex.Lock()
+	user, ok := l.tokens[token]
+	l.mutex.Unlock()
+
+	if !ok {
+		l.writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "invalid_token"})
+		return
+	}
+
+	l.writeJSON(w, http.StatusOK, map[string]interface{}{"user_id": user.UserID, "email": user.Email, "name": user.Name})
+}
+
+func (l *LWA) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,136 @@
+package smarthometest_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+	"github.com/betom84/go-alexa/smarthome/gateway"
+	"github.com/betom84/go-alexa/smarthome/smarthometest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerWithFakes(t *testing.T) {
+	lwa := smarthometest.NewLWA("client-id", "client-secret")
+	defer lwa.Close()
+
+	lamp := smarthometest.NewPowerDevice(false)
+	sensor := smarthometest.NewTemperatureSensor(21.48)
+	sensor.SetConnected(false)
+
+	factory := smarthometest.NewDeviceFactory().
+		AddEndpoint(discoverable.Endpoint{EndpointID: "lamp-1", Cookie: common.Cookie{Type: "light", ID: "L1"}}, lamp).
+		AddEndpoint(discoverable.Endpoint{EndpointID: "sensor-1", Cookie: common.Cookie{Type: "sensor", ID: "S1"}}, sensor)
+
+	tokens := &gateway.MemoryTokenStore{}
+	authority := smarthome.Authority{ClientID: "client-id", ClientSecret: "client-secret", Tokens: tokens, Provider: lwa.Provider()}
+
+	handler := smarthome.NewDefaultHandlerWithSource(authority, factory)
+	handler.DeviceFactory = factory
+	handler.ScopeVerifier = lwa
+
+	code, granteeToken := lwa.Grant(common.Identity{Email: "jane@example.com", UserID: "amzn1.account.jane", Name: "Jane"})
+
+	resp := smarthometest.Serve(t, handler, smarthometest.AcceptGrant(code, granteeToken))
+	smarthometest.AssertResponse(t, resp, "Alexa.Authorization", "AcceptGrant.Response")
+
+	resp = smarthometest.Serve(t, handler, smarthometest.AcceptGrant(code, granteeToken))
+	smarthometest.AssertErrorResponse(t, resp, "INTERNAL_ERROR")
+
+	resp = smarthometest.Serve(t, handler, smarthometest.Discover().WithToken(granteeToken))
+	smarthometest.AssertDiscovered(t, resp, "lamp-1", "sensor-1")
+
+	resp = smarthometest.Serve(t, handler, smarthometest.TurnOn("lamp-1").WithCookie("light", "L1", "Lamp").WithToken(granteeToken))
+	smarthometest.AssertResponse(t, resp, "Alexa", "Response")
+	smarthometest.AssertEndpoint(t, resp, "lamp-1")
+	smarthometest.AssertProperty(t, resp, "Alexa.PowerController", "powerState", "ON")
+	assert.True(t, lamp.IsOn())
+	assert.Equal(t, 1, lamp.SetStateCalls())
+
+	resp = smarthometest.Serve(t, handler, smarthometest.ReportState("sensor-1").WithCookie("sensor", "S1", "").WithToken(granteeToken))
+	smarthometest.AssertResponse(t, resp, "Alexa", "StateReport")
+	smarthometest.AssertProperty(t, resp, "Alexa.TemperatureSensor", "temperature", map[string]interface{}{"value": 21.4, "scale": "CELSIUS"})
+	smarthometest.AssertProperty(t, resp, "Alexa.EndpointHealth", "connectivity", map[string]interface{}{"value": "UNREACHABLE"})
+	smarthometest.AssertNoProperty(t, resp, "Alexa.PowerController", "powerState")
+
+	resp = smarthometest.Serve(t, handler, smarthometest.ReportState("unknown").WithCookie("light", "L2", "").WithToken(granteeToken))
+	smarthometest.AssertErrorResponse(t, resp, "NO_SUCH_ENDPOINT")
+
+	resp = smarthometest.Serve(t, handler, smarthometest.ReportState("lamp-1").WithCookie("light", "L1", ""))
+	smarthometest.AssertErrorResponse(t, resp, "INVALID_AUTHORIZATION_CREDENTIAL")
+
+	token, err := tokens.Token("jane@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, granteeToken, token.GranteeToken)
+	assert.NotEmpty(t, token.AccessToken)
+
+	identity, err := lwa.Verify(common.Scope{Token: token.AccessToken})
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@example.com", identity.Email)
+
+	_, err = lwa.Verify(common.Scope{Token: token.RefreshToken})
+	assert.Error(t, err, "refresh tokens must not be accepted as bearer token")
+}
+
+func TestEventGateway(t *testing.T) {
+	events := smarthometest.NewEventGateway()
+	defer events.Close()
+
+	tokens := &gateway.MemoryTokenStore{}
+	_ = tokens.Store("jane@example.com", gateway.Token{AccessToken: "access-token-1"})
+
+	gw := events.Gateway(tokens)
+	scope, err := gw.Scope("jane@example.com")
+	assert.NoError(t, err)
+
+	event := new(common.Response)
+	event.Event.Header = common.NewHeader("ChangeReport", "Alexa")
+	assert.NoError(t, gw.Send(scope, event))
+
+	received := events.Events()
+	if assert.Len(t, received, 1) {
+		assert.Equal(t, "access-token-1", received[0].Token)
+		smarthometest.AssertResponse(t, received[0].Response, "Alexa", "ChangeReport")
+	}
+
+	events.RespondWith(http.StatusForbidden)
+	assert.Error(t, gw.Send(scope, event))
+	assert.Len(t, events.Events(), 2)
+
+	events.Reset()
+	assert.Empty(t, events.Events())
+}
+
+func TestLWARefreshToken(t *testing.T) {
+	lwa := smarthometest.NewLWA("client-id", "client-secret")
+	defer lwa.Close()
+
+	code, _ := lwa.Grant(common.Identity{Email: "jane@example.com"})
+
+	tokens, err := lwa.Provider().ExchangeCode(code, "client-id", "client-secret")
+	assert.NoError(t, err)
+
+	_, err = lwa.Provider().ExchangeCode(code, "client-id", "client-secret")
+	assert.Error(t, err, "grant codes must only be exchanged once")
+
+	code, _ = lwa.Grant(common.Identity{Email: "jane@example.com"})
+	_, err = lwa.Provider().ExchangeCode(code, "client-id", "wrong-secret")
+	assert.Error(t, err, "client credentials must be verified")
+
+	store := &gateway.MemoryTokenStore{}
+	_ = store.Store("jane@example.com", gateway.Token{RefreshToken: tokens["refresh_token"].(string), Expiry: gateway.Now().Add(-1)})
+
+	gateway.RefreshTokenURL = lwa.TokenURL()
+	defer func() { gateway.RefreshTokenURL = "https://api.amazon.com/auth/o2/token" }()
+
+	gw := &gateway.Gateway{ClientID: "client-id", ClientSecret: "client-secret", Tokens: store, Client: lwa.Client()}
+	scope, err := gw.Scope("jane@example.com")
+	assert.NoError(t, err)
+
+	profile, err := lwa.Provider().Profile(scope.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@example.com", profile.Email)
+}
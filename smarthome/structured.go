@@ -0,0 +1,146 @@
+package smarthome
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/redact"
+)
+
+// Field is a key/value pair attached to structured log entries
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Any creates a field of the given key and value
+func Any(key string, value interface{}) Field {
+	return Field{key, value}
+}
+
+// StructuredLogger logs messages with key/value fields, levels are the same as used by NewDefaultLogger
+type StructuredLogger interface {
+	// Log the message with the given fields
+	Log(level int, message string, fields ...Field)
+
+	// With returns a logger adding the given fields to every message
+	With(fields ...Field) StructuredLogger
+}
+
+// FromLogger adapts a printf Logger to a StructuredLogger, fields are appended to the message as key=value.
+// If logger is nil, the package logger Log is used at the time of logging.
+func FromLogger(logger Logger) StructuredLogger {
+	return legacyLogger{logger: logger}
+}
+
+type legacyLogger struct {
+	logger Logger
+	fields []Field
+}
+
+func (l legacyLogger) Log(level int, message string, fields ...Field) {
+	logger := l.logger
+	if logger == nil {
+		logger = Log
+	}
+
+	var buffer strings.Builder
+	buffer.WriteString(message)
+
+	for _, field := range append(l.fields[:len(l.fields):len(l.fields)], fields...) {
+		buffer.WriteString(" ")
+		buffer.WriteString(field.Key)
+		buffer.WriteString("=")
+		buffer.WriteString(formatFieldValue(field.Value))
+	}
+
+	switch level {
+	case Trace:
+		logger.Trace("%s", buffer.String())
+	case Debug:
+		logger.Debug("%s", buffer.String())
+	case Info:
+		logger.Info("%s", buffer.String())
+	case Warning:
+		logger.Warning("%s", buffer.String())
+	case Error:
+		logger.Error("%s", buffer.String())
+	default:
+		logger.Fatal("%s", buffer.String())
+	}
+}
+
+func (l legacyLogger) With(fields ...Field) StructuredLogger {
+	return legacyLogger{logger: l.logger, fields: append(l.fields[:len(l.fields):len(l.fields)], fields...)}
+}
+
+func formatFieldValue(value interface{}) string {
+	s := fmt.Sprintf("%v", value)
+	if s == "" || strings.ContainsAny(s, " =\"\n\t") {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return s
+}
+
+// Redacting wraps a logger to mask sensitive values of fields, like scope tokens and grant codes
+func Redacting(logger StructuredLogger, redactor *redact.Redactor) StructuredLogger {
+	return redactingLogger{logger, redactor}
+}
+
+type redactingLogger struct {
+	logger   StructuredLogger
+	redactor *redact.Redactor
+}
+
+func (l redactingLogger) Log(level int, message string, fields ...Field) {
+	l.logger.Log(level, l.redactor.String(message), l.redact(fields)...)
+}
+
+func (l redactingLogger) With(fields ...Field) StructuredLogger {
+	return redactingLogger{l.logger.With(l.redact(fields)...), l.redactor}
+}
+
+func (l redactingLogger) redact(fields []Field) []Field {
+	redacted := make([]Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = Field{field.Key, l.redactor.Field(field.Key, field.Value)}
+	}
+
+	return redacted
+}
+
+var defaultRedactor = redact.New()
+
+func (h *Handler) logger() StructuredLogger {
+	logger := h.Logger
+	if logger == nil {
+		logger = FromLogger(nil)
+	}
+
+	redactor := h.Redactor
+	if redactor == nil {
+		redactor = defaultRedactor
+	}
+
+	return Redacting(logger, redactor)
+}
+
+func directiveFields(dir *common.Directive) []Field {
+	fields := []Field{
+		Any("namespace", dir.Header.Namespace),
+		Any("name", dir.Header.Name),
+		Any("messageId", dir.Header.MessageID),
+	}
+
+	if dir.Header.CorrelationToken != "" {
+		fields = append(fields, Any("correlationToken", dir.Header.CorrelationToken))
+	}
+
+	if dir.Endpoint != nil {
+		fields = append(fields, Any("endpointId", dir.Endpoint.EndpointID))
+	}
+
+	return fields
+}
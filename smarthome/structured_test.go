@@ -0,0 +1,99 @@
+package smarthome_test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/identity"
+	"github.com/betom84/go-alexa/smarthome/redact"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type logEntry struct {
+	level   int
+	message string
+	fields  map[string]interface{}
+}
+
+type recordingLogger struct {
+	entries *[]logEntry
+	fields  []smarthome.Field
+}
+
+func (l recordingLogger) Log(level int, message string, fields ...smarthome.Field) {
+	entry := logEntry{level, message, map[string]interface{}{}}
+	for _, f := range append(append([]smarthome.Field{}, l.fields...), fields...) {
+		entry.fields[f.Key] = f.Value
+	}
+
+	*l.entries = append(*l.entries, entry)
+}
+
+func (l recordingLogger) With(fields ...smarthome.Field) smarthome.StructuredLogger {
+	return recordingLogger{l.entries, append(append([]smarthome.Field{}, l.fields...), fields...)}
+}
+
+func TestFromLogger(t *testing.T) {
+	builder := &strings.Builder{}
+	logger := smarthome.FromLogger(smarthome.NewDefaultLogger(smarthome.Debug, builder)).With(smarthome.Any("messageId", "abc-123"))
+
+	logger.Log(smarthome.Warning, "Something happened", smarthome.Any("endpointId", "appliance-001"), smarthome.Any("error", "went wrong"))
+	assert.True(t, strings.HasSuffix(builder.String(), "[go-alexa] [WARN] Something happened messageId=abc-123 endpointId=appliance-001 error=\"went wrong\"\n"), builder.String())
+	builder.Reset()
+
+	logger.Log(smarthome.Trace, "Filtered by level")
+	assert.Empty(t, builder.String())
+}
+
+func TestHandlerLogsWithFields(t *testing.T) {
+	var entries []logEntry
+
+	handler := smarthome.Handler{
+		DeviceFactory: createMockDeviceFactory("testing", "ABC-123"),
+		Logger:        recordingLogger{entries: &entries},
+		ScopeVerifier: identity.VerifierFunc(func(scope common.Scope) (*common.Identity, error) {
+			return &common.Identity{Email: "somebody@mail.com"}, nil
+		}),
+	}
+	handler.AddDirectiveProcessor(createMockDirectiveProcessor(true, common.NewInternalError("something horrible")))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", bytes.NewReader(readFile(t, "testdata/process_directive_request.json"))))
+
+	var logged *logEntry
+	for i := range entries {
+		if entries[i].message == "Unable to process directive" {
+			logged = &entries[i]
+		}
+	}
+
+	if assert.NotNil(t, logged, "expected error to be logged") {
+		assert.Equal(t, smarthome.Error, logged.level)
+		assert.Equal(t, "Something.Processable", logged.fields["namespace"])
+		assert.Equal(t, "dFMb0z+PgpgdDmluhJ1LddFvSqZ/jCc8ptlAKulUj90jSqg==", logged.fields["correlationToken"])
+		assert.Equal(t, "appliance-001", logged.fields["endpointId"])
+		assert.Equal(t, "somebody@mail.com", logged.fields["user"])
+		assert.Equal(t, common.NewInternalError("something horrible"), logged.fields["error"])
+		assert.Contains(t, logged.fields, "messageId")
+	}
+}
+
+func TestHandlerRedactsLogs(t *testing.T) {
+	var entries []logEntry
+
+	handler := smarthome.Handler{Logger: recordingLogger{entries: &entries}}
+	handler.Redactor = redact.New("payload.secret")
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(`{"directive":{"payload":{"grant":{"code":"grant-code"},"secret":"my-secret"}`)))
+
+	if assert.NotEmpty(t, entries) {
+		body := entries[0].fields["body"].(string)
+		assert.NotContains(t, body, "grant-code")
+		assert.NotContains(t, body, "my-secret")
+		assert.Contains(t, body, `"code":"[REDACTED]"`)
+	}
+}
@@ -0,0 +1,124 @@
+package smarthome_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/betom84/go-alexa/smarthome"
+	"github.com/betom84/go-alexa/smarthome/common"
+	"github.com/betom84/go-alexa/smarthome/common/discoverable"
+	"github.com/betom84/go-alexa/smarthome/directives/discovery"
+	"github.com/betom84/go-alexa/smarthome/identity"
+	"github.com/betom84/go-alexa/smarthome/testdata/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func createMultiTenantAuthority() smarthome.Authority {
+	return smarthome.Authority{
+		Users: map[string]smarthome.User{
+			"somebody@mail.com": {Endpoints: discovery.StaticEndpoints{{EndpointID: "appliance-001", Cookie: common.Cookie{Type: "testing", ID: "ABC-123"}}}},
+			"anybody@mail.com":  {Endpoints: discovery.StaticEndpoints{{EndpointID: "appliance-002", Cookie: common.Cookie{Type: "heater", ID: "household-b-heater"}}}},
+			"nobody@mail.com":   {},
+		},
+	}
+}
+
+func TestAuthorityUsers(t *testing.T) {
+	authority := createMultiTenantAuthority()
+	authority.RestrictedUsers = []string{"legacy@mail.com"}
+
+	assert.NoError(t, authority.AcceptGrant("somebody@mail.com", "", nil))
+	assert.NoError(t, authority.AcceptGrant("legacy@mail.com", "", nil))
+	assert.Error(t, authority.AcceptGrant("stranger@mail.com", "", nil))
+
+	assert.Equal(t, []string{"anybody@mail.com", "nobody@mail.com", "somebody@mail.com"}, authority.UserNames())
+
+	endpoints, err := authority.Endpoints(common.Scope{Identity: &common.Identity{Email: "somebody@mail.com"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []discoverable.Endpoint{{EndpointID: "appliance-001", Cookie: common.Cookie{Type: "testing", ID: "ABC-123"}}}, endpoints)
+
+	endpoints, err = authority.Endpoints(common.Scope{Identity: &common.Identity{Email: "nobody@mail.com"}})
+	assert.NoError(t, err)
+	assert.Empty(t, endpoints)
+
+	endpoints, err = authority.Endpoints(common.Scope{Identity: &common.Identity{Email: "stranger@mail.com"}})
+	assert.NoError(t, err)
+	assert.Empty(t, endpoints)
+
+	_, err = authority.Endpoints(common.Scope{})
+	assert.EqualError(t, err, "identity of scope is unknown")
+}
+
+func TestMultiTenantHandler(t *testing.T) {
+	common.ConstMessageID = "any-const-message-id-for-test"
+
+	verifier := identity.VerifierFunc(func(scope common.Scope) (*common.Identity, error) {
+		return &common.Identity{Email: scope.Token}, nil
+	})
+
+	request := func(token string) []byte {
+		return bytes.Replace(readFile(t, "testdata/process_directive_request.json"), []byte("access-token-from-skill"), []byte(token), 1)
+	}
+
+	tt := []struct {
+		name             string
+		request          []byte
+		expectedResponse []byte
+		processed        bool
+	}{
+		{
+			name:             "it processes directives for endpoints of the user",
+			request:          request("somebody@mail.com"),
+			expectedResponse: []byte(`{"event":{"header":null}}`),
+			processed:        true,
+		},
+		{
+			name:             "it rejects directives for endpoints of other users",
+			request:          request("anybody@mail.com"),
+			expectedResponse: []byte(`{"event":{"header":{"namespace":"Alexa","name":"ErrorResponse","messageId":"any-const-message-id-for-test","correlationToken":"dFMb0z+PgpgdDmluhJ1LddFvSqZ/jCc8ptlAKulUj90jSqg==","payloadVersion":"3"},"endpoint":{"endpointId":"appliance-001","scope":{"type":"BearerToken","token":"anybody@mail.com"},"cookie":{"id":"ABC-123","name":"Device for testing","type":"testing"}},"payload":{"type":"NO_SUCH_ENDPOINT","message":"endpoint appliance-001 does not exist"}}}`),
+		},
+		{
+			name:             "it rejects directives for own endpoints with the cookie of another user",
+			request:          bytes.Replace(bytes.Replace(request("somebody@mail.com"), []byte(`"testing"`), []byte(`"heater"`), 1), []byte(`"ABC-123"`), []byte(`"household-b-heater"`), 1),
+			expectedResponse: []byte(`{"event":{"header":{"namespace":"Alexa","name":"ErrorResponse","messageId":"any-const-message-id-for-test","correlationToken":"dFMb0z+PgpgdDmluhJ1LddFvSqZ/jCc8ptlAKulUj90jSqg==","payloadVersion":"3"},"endpoint":{"endpointId":"appliance-001","scope":{"type":"BearerToken","token":"somebody@mail.com"},"cookie":{"id":"household-b-heater","name":"Device for testing","type":"heater"}},"payload":{"type":"NO_SUCH_ENDPOINT","message":"endpoint appliance-001 does not exist"}}}`),
+		},
+		{
+			name:             "it rejects directives for endpoints of unknown users",
+			request:          request("stranger@mail.com"),
+			expectedResponse: []byte(`{"event":{"header":{"namespace":"Alexa","name":"ErrorResponse","messageId":"any-const-message-id-for-test","correlationToken":"dFMb0z+PgpgdDmluhJ1LddFvSqZ/jCc8ptlAKulUj90jSqg==","payloadVersion":"3"},"endpoint":{"endpointId":"appliance-001","scope":{"type":"BearerToken","token":"stranger@mail.com"},"cookie":{"id":"ABC-123","name":"Device for testing","type":"testing"}},"payload":{"type":"NO_SUCH_ENDPOINT","message":"endpoint appliance-001 does not exist"}}}`),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := smarthome.NewMultiTenantHandler(createMultiTenantAuthority(), verifier)
+
+			f := &mocks.MockUserDeviceFactory{}
+			f.On("NewUserDevice", mock.Anything, "testing", "ABC-123").Return("device", nil)
+			handler.DeviceFactory = f
+			defer f.AssertNotCalled(t, "NewUserDevice", mock.Anything, "heater", mock.Anything)
+
+			p := &mocks.MockDirectiveProcessor{}
+			p.On("IsCapable", mock.MatchedBy(func(dir *common.Directive) bool { return dir.Header.Namespace == "Something.Processable" })).Return(true)
+			p.On("IsCapable", mock.Anything).Return(false)
+			p.On("Process", mock.Anything, "device").Return(&common.Response{}, nil)
+			handler.AddDirectiveProcessor(p)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(tc.request)))
+
+			body, _ := ioutil.ReadAll(rec.Result().Body)
+			assert.JSONEq(t, string(tc.expectedResponse), string(body))
+
+			if tc.processed {
+				p.AssertCalled(t, "Process", mock.Anything, "device")
+			} else {
+				p.AssertNotCalled(t, "Process", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
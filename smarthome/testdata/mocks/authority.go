@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"github.com/betom84/go-alexa/smarthome/common"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -26,3 +28,22 @@ func (a *MockAuthority) AcceptGrant(email string, bearerToken string, accessToke
 	r := a.Called(email, bearerToken, accessTokens)
 	return r.Error(0)
 }
+
+// MockIdentityProvider ...
+type MockIdentityProvider struct {
+	mock.Mock
+}
+
+// ExchangeCode ...
+func (p *MockIdentityProvider) ExchangeCode(code string, clientID string, clientSecret string) (map[string]interface{}, error) {
+	r := p.Called(code, clientID, clientSecret)
+	tokens, _ := r.Get(0).(map[string]interface{})
+	return tokens, r.Error(1)
+}
+
+// Profile ...
+func (p *MockIdentityProvider) Profile(granteeToken string) (*common.Identity, error) {
+	r := p.Called(granteeToken)
+	profile, _ := r.Get(0).(*common.Identity)
+	return profile, r.Error(1)
+}
@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"github.com/betom84/go-alexa/smarthome/common"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockEventGateway ...
+type MockEventGateway struct {
+	mock.Mock
+}
+
+// Users ...
+func (g *MockEventGateway) Users() ([]string, error) {
+	r := g.Called()
+	return r.Get(0).([]string), r.Error(1)
+}
+
+// Scope ...
+func (g *MockEventGateway) Scope(user string) (common.Scope, error) {
+	r := g.Called(user)
+	return r.Get(0).(common.Scope), r.Error(1)
+}
+
+// GranteeScope ...
+func (g *MockEventGateway) GranteeScope(user string) (common.Scope, error) {
+	r := g.Called(user)
+	return r.Get(0).(common.Scope), r.Error(1)
+}
+
+// Send ...
+func (g *MockEventGateway) Send(scope common.Scope, event *common.Response) error {
+	r := g.Called(scope, event)
+	return r.Error(0)
+}
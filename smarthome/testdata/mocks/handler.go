@@ -33,3 +33,14 @@ func (p *MockDirectiveProcessor) Process(dir *common.Directive, device interface
 	r := p.Called(dir, device)
 	return r.Get(0).(*common.Response), r.Error(1)
 }
+
+// MockUserDeviceFactory ...
+type MockUserDeviceFactory struct {
+	MockDeviceFactory
+}
+
+// NewUserDevice ...
+func (f *MockUserDeviceFactory) NewUserDevice(user *common.Identity, epType string, id string) (interface{}, error) {
+	r := f.Called(user, epType, id)
+	return r.Get(0), r.Error(1)
+}
@@ -23,8 +23,8 @@ func (h *MockHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer func() { _ = r.Body.Close() }()
 
 	resp, status := h.RequestURI(r.Method, r.RequestURI, body)
-	_, _ = w.Write(resp)
 	w.WriteHeader(status)
+	_, _ = w.Write(resp)
 }
 
 // RequestURI is used to expect requests made to the mocked handler
@@ -2,14 +2,29 @@
 package validator
 
 import (
+	_ "embed" // embeds the official schema
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/xeipuuv/gojsonschema"
 )
 
+// SchemaURL of the latest official alexa smart home message schema, use it as SchemaReference to validate
+// against the latest version instead of the embedded one
+const SchemaURL = "https://raw.githubusercontent.com/alexa/alexa-smarthome/master/validation_schemas/alexa_smart_home_message_schema.json"
+
+//go:embed alexa_smart_home_message_schema.json
+var officialSchema []byte
+
+var embedded struct {
+	once   sync.Once
+	schema *gojsonschema.Schema
+	err    error
+}
+
 // ValidationError encapsulate gojsonschema result errors
 type ValidationError struct {
 	errors []gojsonschema.ResultError
@@ -22,27 +37,24 @@ func (e ValidationError) Error() (message string) {
 	return
 }
 
-// Validator can validate []byte response against his json schema
+// Validator can validate []byte response against his json schema. The schema is compiled on first use,
+// the validator is safe for concurrent use afterwards.
 type Validator struct {
-	// SchemaReference points to a file or an url
+	// SchemaReference points to a file or an url, defaults to the official schema embedded in this package
 	SchemaReference string
 
-	schemaBytes []byte
+	mutex  sync.Mutex
+	schema *gojsonschema.Schema
 }
 
 // Validate the given response against the json schema
 func (v *Validator) Validate(resp []byte) error {
-	if len(v.schemaBytes) == 0 {
-		err := v.loadSchemaBytes()
-		if err != nil {
-			return err
-		}
+	schema, err := v.compiledSchema()
+	if err != nil {
+		return err
 	}
 
-	schemaLoader := gojsonschema.NewBytesLoader(v.schemaBytes)
-	responseLoader := gojsonschema.NewBytesLoader(resp)
-
-	result, err := gojsonschema.Validate(schemaLoader, responseLoader)
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(resp))
 	if err != nil {
 		return err
 	}
@@ -54,22 +66,50 @@ func (v *Validator) Validate(resp []byte) error {
 	return nil
 }
 
-func (v *Validator) loadSchemaBytes() error {
+func (v *Validator) compiledSchema() (*gojsonschema.Schema, error) {
 	if v.SchemaReference == "" {
-		v.SchemaReference = "https://raw.githubusercontent.com/alexa/alexa-smarthome/master/validation_schemas/alexa_smart_home_message_schema.json"
+		embedded.once.Do(func() {
+			embedded.schema, embedded.err = gojsonschema.NewSchema(gojsonschema.NewBytesLoader(officialSchema))
+		})
+
+		return embedded.schema, embedded.err
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.schema != nil {
+		return v.schema, nil
+	}
+
+	// a schema which failed to load is not cached, so it gets loaded again on the next validation
+	schemaBytes, err := v.loadSchemaBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	v.schema, err = gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schemaBytes))
+	if err != nil {
+		return nil, fmt.Errorf("could not compile schema %s; %v", v.SchemaReference, err)
+	}
+
+	return v.schema, nil
+}
+
+func (v *Validator) loadSchemaBytes() ([]byte, error) {
+	if !strings.HasPrefix(v.SchemaReference, "http") {
+		return ioutil.ReadFile(v.SchemaReference)
+	}
+
+	r, err := http.Get(v.SchemaReference)
+	if err != nil {
+		return nil, err
 	}
+	defer func() { _ = r.Body.Close() }()
 
-	var err error
-	if strings.HasPrefix(v.SchemaReference, "http") {
-		var r *http.Response
-		r, err = http.Get(v.SchemaReference)
-		if err != nil {
-			return err
-		}
-		v.schemaBytes, err = ioutil.ReadAll(r.Body)
-	} else {
-		v.schemaBytes, err = ioutil.ReadFile(v.SchemaReference)
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not load schema %s; %s", v.SchemaReference, r.Status)
 	}
 
-	return err
+	return ioutil.ReadAll(r.Body)
 }
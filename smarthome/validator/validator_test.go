@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/betom84/go-alexa/smarthome/validator"
@@ -15,7 +16,7 @@ type Handler struct {
 }
 
 func (h Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	content, err := ioutil.ReadFile("testdata/schema.json")
+	content, err := ioutil.ReadFile("alexa_smart_home_message_schema.json")
 	if err != nil {
 		h.t.Fatalf("could not read json schema; %v", err)
 	}
@@ -28,19 +29,19 @@ func TestValidator(t *testing.T) {
 
 	tt := []struct {
 		name      string
-		validator validator.Validator
+		validator *validator.Validator
 	}{
 		{
 			name:      "validate with a file schema reference",
-			validator: validator.Validator{SchemaReference: "testdata/schema.json"},
+			validator: &validator.Validator{SchemaReference: "alexa_smart_home_message_schema.json"},
 		},
 		{
 			name:      "validate with a url schema reference",
-			validator: validator.Validator{SchemaReference: srv.URL},
+			validator: &validator.Validator{SchemaReference: srv.URL},
 		},
 		{
-			name:      "validate with default url schema reference",
-			validator: validator.Validator{SchemaReference: ""},
+			name:      "validate with embedded schema",
+			validator: &validator.Validator{},
 		},
 	}
 
@@ -51,7 +52,7 @@ func TestValidator(t *testing.T) {
 	}
 }
 
-func testValidator(v validator.Validator, t *testing.T) {
+func testValidator(v *validator.Validator, t *testing.T) {
 	tt := []struct {
 		name  string
 		value []byte
@@ -86,3 +87,43 @@ func testValidator(v validator.Validator, t *testing.T) {
 		})
 	}
 }
+
+func TestValidatorSchemaErrors(t *testing.T) {
+	v := &validator.Validator{SchemaReference: "testdata/missing.json"}
+	if err := v.Validate([]byte(`{}`)); err == nil {
+		t.Fatal("validate returned no error for a missing schema")
+	}
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	v = &validator.Validator{SchemaReference: srv.URL}
+	if err := v.Validate([]byte(`{}`)); err == nil || !strings.Contains(err.Error(), "404 Not Found") {
+		t.Fatalf("validate returned unexpected error; %v", err)
+	}
+}
+
+func TestValidatorConcurrentUse(t *testing.T) {
+	v := &validator.Validator{}
+	valid := []byte(`{"event":{"header":{"namespace":"Alexa","name":"Response","messageId":"1","payloadVersion":"3"},"payload":{}}}`)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- v.Validate(valid)
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("validate returned an error without expecting one; %v", err)
+		}
+	}
+}